@@ -0,0 +1,109 @@
+// Package book holds the opening book: strong first placements on an empty
+// grid, searched offline for every pattern of the first pairs.
+//
+// Only the pattern of the colours matters, not the colours themselves, so
+// pairs are normalised by numbering their colours in the order they first
+// appear. Blue-green then green-yellow and red-pink then pink-blue are both
+// 12 23.
+package book
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+// Pairs the book is keyed on and placements it plays
+const PAIRS int = 3
+
+// Move is a placement the book plays.
+type Move struct {
+	Position int
+	Rotation int
+}
+
+// Key is the normalised pattern of the pairs, its colours numbered in the
+// order they first appear.
+func Key(pairs [][2]uint8) string {
+	var numbers [256]uint8
+	var next uint8 = 1
+	var key []string
+
+	for _, pair := range pairs {
+		var text [2]byte
+		for i, colour := range pair {
+			if numbers[colour] == 0 {
+				numbers[colour] = next
+				next++
+			}
+			text[i] = '0' + numbers[colour]
+		}
+		key = append(key, string(text[:]))
+	}
+
+	return strings.Join(key, " ")
+}
+
+// Lookup returns the book's line for the queue seen on an empty grid, nil
+// if the book has none.
+func Lookup(pairs *[engine.KNOWN_PAIRS][2]uint8) []Move {
+	return openings[Key(pairs[:PAIRS])]
+}
+
+// Patterns lists one sequence of pairs for every normalised pattern of the
+// given length, in the order of their keys.
+func Patterns(length int) [][][2]uint8 {
+	var patterns [][][2]uint8
+
+	var colours []uint8 = make([]uint8, 2*length)
+	var extend func(i int, used uint8)
+	extend = func(i int, used uint8) {
+		if i == len(colours) {
+			var pairs [][2]uint8
+			for p := 0; p < length; p++ {
+				pairs = append(pairs, [2]uint8{colours[2*p], colours[2*p+1]})
+			}
+			patterns = append(patterns, pairs)
+			return
+		}
+
+		// A colour seen already, or the next new one
+		for colour := uint8(1); colour <= used+1 && colour <= 5; colour++ {
+			colours[i] = colour
+			if colour > used {
+				extend(i+1, colour)
+			} else {
+				extend(i+1, used)
+			}
+		}
+	}
+	extend(0, 0)
+
+	return patterns
+}
+
+// Write generates the Go source of a book holding the given lines.
+func Write(w io.Writer, command string, openings map[string][]Move) error {
+	var keys []string
+	for key := range openings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var source strings.Builder
+	fmt.Fprintf(&source, "// Code generated by %s. DO NOT EDIT.\n\npackage book\n\nvar openings = map[string][]Move{\n", command)
+	for _, key := range keys {
+		var moves []string
+		for _, move := range openings[key] {
+			moves = append(moves, fmt.Sprintf("{%d, %d}", move.Position, move.Rotation))
+		}
+		fmt.Fprintf(&source, "\t%q: {%s},\n", key, strings.Join(moves, ", "))
+	}
+	source.WriteString("}\n")
+
+	_, err := io.WriteString(w, source.String())
+	return err
+}
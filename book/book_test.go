@@ -0,0 +1,71 @@
+package book
+
+import (
+	"bytes"
+	"go/format"
+	"testing"
+
+	"github.com/edwardadd/smash_the_code/engine"
+	"github.com/edwardadd/smash_the_code/timing"
+)
+
+func TestKeyIgnoresColours(t *testing.T) {
+	a := Key([][2]uint8{{1, 2}, {2, 5}, {3, 3}})
+	b := Key([][2]uint8{{4, 3}, {3, 1}, {2, 2}})
+	if a != "12 23 44" || a != b {
+		t.Fatalf("Expected both keys to be 12 23 44, got %q and %q", a, b)
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	patterns := Patterns(PAIRS)
+
+	// Set partitions of six blocks into at most five colours
+	if len(patterns) != 202 {
+		t.Fatalf("Expected 202 patterns, got %d", len(patterns))
+	}
+
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		key := Key(pattern)
+		if seen[key] {
+			t.Fatalf("Pattern %s listed twice", key)
+		}
+		seen[key] = true
+	}
+}
+
+func TestOpeningsAreLegal(t *testing.T) {
+	target := timing.DefaultPolicy().TargetChain
+	for _, pattern := range Patterns(PAIRS) {
+		line, ok := openings[Key(pattern)]
+		if !ok {
+			t.Fatalf("No opening for %s", Key(pattern))
+		}
+
+		grid, _ := engine.GridFromRows(nil)
+		for i, move := range line {
+			resolution, err := engine.Place(&grid, move.Position, move.Rotation, pattern[i])
+			if err != nil {
+				t.Fatalf("Opening for %s is illegal at %d: %v", Key(pattern), i, err)
+			}
+
+			// Short chains waste the blocks an opening is there to lay
+			if resolution.ChainCount > 0 && resolution.ChainCount < target {
+				t.Fatalf("Opening for %s fires a chain of %d at %d", Key(pattern), resolution.ChainCount, i)
+			}
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var source bytes.Buffer
+	if err := Write(&source, "test", map[string][]Move{"11 12 23": {{2, 1}, {0, 0}, {5, 3}}}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil || !bytes.Equal(formatted, source.Bytes()) {
+		t.Fatalf("Generated source is not formatted Go\n%s", source.String())
+	}
+}
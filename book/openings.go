@@ -0,0 +1,208 @@
+// Code generated by smash book. DO NOT EDIT.
+
+package book
+
+var openings = map[string][]Move{
+	"11 11 11": {{3, 1}, {0, 1}, {5, 1}},
+	"11 11 12": {{2, 0}, {0, 1}, {3, 2}},
+	"11 11 21": {{2, 1}, {4, 0}, {0, 0}},
+	"11 11 22": {{4, 0}, {2, 1}, {1, 1}},
+	"11 11 23": {{4, 0}, {0, 1}, {2, 0}},
+	"11 12 11": {{2, 2}, {4, 2}, {5, 1}},
+	"11 12 12": {{2, 1}, {3, 0}, {5, 2}},
+	"11 12 13": {{3, 1}, {4, 0}, {0, 0}},
+	"11 12 21": {{2, 1}, {2, 0}, {3, 0}},
+	"11 12 22": {{2, 2}, {2, 0}, {4, 0}},
+	"11 12 23": {{2, 0}, {2, 0}, {2, 2}},
+	"11 12 31": {{2, 1}, {2, 0}, {4, 0}},
+	"11 12 32": {{2, 1}, {2, 0}, {4, 2}},
+	"11 12 33": {{2, 1}, {3, 1}, {2, 1}},
+	"11 12 34": {{2, 1}, {2, 0}, {4, 0}},
+	"11 21 11": {{2, 2}, {3, 0}, {5, 1}},
+	"11 21 12": {{2, 0}, {3, 0}, {1, 3}},
+	"11 21 13": {{0, 1}, {4, 0}, {3, 2}},
+	"11 21 21": {{2, 1}, {2, 0}, {3, 2}},
+	"11 21 22": {{2, 2}, {4, 0}, {4, 2}},
+	"11 21 23": {{2, 1}, {3, 2}, {3, 0}},
+	"11 21 31": {{2, 0}, {2, 0}, {1, 2}},
+	"11 21 32": {{2, 1}, {4, 0}, {3, 0}},
+	"11 21 33": {{4, 2}, {5, 2}, {2, 1}},
+	"11 21 34": {{2, 1}, {4, 0}, {1, 2}},
+	"11 22 11": {{2, 2}, {3, 1}, {5, 1}},
+	"11 22 12": {{2, 2}, {4, 0}, {2, 0}},
+	"11 22 13": {{1, 1}, {2, 1}, {4, 2}},
+	"11 22 21": {{2, 2}, {4, 0}, {4, 2}},
+	"11 22 22": {{2, 1}, {2, 0}, {4, 0}},
+	"11 22 23": {{3, 1}, {2, 1}, {2, 2}},
+	"11 22 31": {{2, 1}, {4, 1}, {3, 2}},
+	"11 22 32": {{2, 1}, {1, 2}, {1, 2}},
+	"11 22 33": {{2, 1}, {4, 0}, {1, 1}},
+	"11 22 34": {{2, 1}, {3, 1}, {5, 2}},
+	"11 23 11": {{2, 1}, {4, 0}, {0, 1}},
+	"11 23 12": {{3, 1}, {2, 2}, {3, 2}},
+	"11 23 13": {{2, 1}, {5, 2}, {3, 0}},
+	"11 23 14": {{2, 1}, {4, 0}, {2, 0}},
+	"11 23 21": {{2, 1}, {4, 0}, {4, 2}},
+	"11 23 22": {{2, 2}, {4, 0}, {4, 2}},
+	"11 23 23": {{2, 1}, {0, 0}, {0, 0}},
+	"11 23 24": {{2, 1}, {4, 0}, {4, 2}},
+	"11 23 31": {{2, 1}, {5, 2}, {4, 2}},
+	"11 23 32": {{3, 1}, {1, 2}, {2, 2}},
+	"11 23 33": {{2, 2}, {3, 0}, {5, 1}},
+	"11 23 34": {{2, 1}, {3, 0}, {4, 0}},
+	"11 23 41": {{2, 1}, {2, 0}, {4, 2}},
+	"11 23 42": {{0, 1}, {2, 2}, {3, 2}},
+	"11 23 43": {{1, 1}, {2, 0}, {4, 0}},
+	"11 23 44": {{0, 1}, {4, 0}, {1, 1}},
+	"11 23 45": {{0, 1}, {2, 0}, {4, 0}},
+	"12 11 11": {{2, 0}, {4, 0}, {1, 1}},
+	"12 11 12": {{2, 0}, {1, 1}, {3, 3}},
+	"12 11 13": {{2, 0}, {1, 1}, {4, 0}},
+	"12 11 21": {{2, 0}, {2, 1}, {3, 0}},
+	"12 11 22": {{2, 0}, {2, 2}, {4, 0}},
+	"12 11 23": {{2, 0}, {1, 1}, {0, 0}},
+	"12 11 31": {{2, 0}, {2, 1}, {4, 0}},
+	"12 11 32": {{2, 0}, {1, 1}, {4, 2}},
+	"12 11 33": {{2, 0}, {1, 1}, {2, 1}},
+	"12 11 34": {{2, 0}, {2, 1}, {4, 0}},
+	"12 12 11": {{2, 0}, {4, 0}, {1, 1}},
+	"12 12 12": {{2, 0}, {2, 0}, {2, 0}},
+	"12 12 13": {{4, 0}, {2, 2}, {2, 0}},
+	"12 12 21": {{2, 0}, {2, 2}, {3, 2}},
+	"12 12 22": {{2, 0}, {2, 2}, {4, 0}},
+	"12 12 23": {{2, 2}, {2, 2}, {4, 2}},
+	"12 12 31": {{2, 0}, {4, 2}, {1, 0}},
+	"12 12 32": {{2, 0}, {2, 0}, {4, 2}},
+	"12 12 33": {{2, 0}, {2, 0}, {1, 1}},
+	"12 12 34": {{2, 0}, {2, 0}, {4, 0}},
+	"12 13 11": {{2, 0}, {4, 0}, {1, 1}},
+	"12 13 12": {{2, 0}, {2, 2}, {2, 0}},
+	"12 13 13": {{4, 0}, {4, 2}, {4, 2}},
+	"12 13 14": {{2, 0}, {2, 2}, {4, 0}},
+	"12 13 21": {{4, 2}, {4, 0}, {3, 2}},
+	"12 13 22": {{2, 0}, {2, 2}, {4, 0}},
+	"12 13 23": {{4, 2}, {1, 0}, {3, 2}},
+	"12 13 24": {{2, 0}, {2, 2}, {3, 0}},
+	"12 13 31": {{4, 0}, {2, 0}, {3, 0}},
+	"12 13 32": {{2, 0}, {5, 2}, {4, 2}},
+	"12 13 33": {{2, 2}, {2, 0}, {4, 0}},
+	"12 13 34": {{1, 2}, {1, 0}, {2, 0}},
+	"12 13 41": {{0, 0}, {4, 2}, {5, 2}},
+	"12 13 42": {{4, 0}, {2, 0}, {1, 2}},
+	"12 13 43": {{4, 0}, {4, 2}, {2, 0}},
+	"12 13 44": {{2, 0}, {2, 2}, {5, 1}},
+	"12 13 45": {{2, 0}, {4, 0}, {1, 2}},
+	"12 21 11": {{2, 0}, {4, 0}, {2, 2}},
+	"12 21 12": {{2, 0}, {3, 2}, {2, 0}},
+	"12 21 13": {{4, 2}, {3, 0}, {2, 2}},
+	"12 21 21": {{4, 0}, {5, 2}, {5, 2}},
+	"12 21 22": {{2, 0}, {1, 0}, {4, 0}},
+	"12 21 23": {{0, 0}, {1, 2}, {1, 0}},
+	"12 21 31": {{2, 0}, {3, 2}, {1, 0}},
+	"12 21 32": {{1, 0}, {2, 2}, {3, 2}},
+	"12 21 33": {{4, 0}, {5, 2}, {3, 1}},
+	"12 21 34": {{0, 0}, {1, 2}, {4, 0}},
+	"12 22 11": {{2, 0}, {4, 0}, {2, 2}},
+	"12 22 12": {{2, 0}, {4, 1}, {2, 1}},
+	"12 22 13": {{2, 0}, {4, 1}, {2, 2}},
+	"12 22 21": {{2, 0}, {4, 1}, {2, 3}},
+	"12 22 22": {{2, 0}, {1, 2}, {4, 1}},
+	"12 22 23": {{2, 0}, {4, 1}, {1, 2}},
+	"12 22 31": {{2, 0}, {4, 1}, {1, 0}},
+	"12 22 32": {{2, 0}, {4, 1}, {1, 2}},
+	"12 22 33": {{2, 0}, {4, 1}, {3, 1}},
+	"12 22 34": {{2, 0}, {4, 1}, {1, 2}},
+	"12 23 11": {{2, 0}, {4, 0}, {2, 2}},
+	"12 23 12": {{1, 2}, {4, 0}, {1, 2}},
+	"12 23 13": {{2, 0}, {0, 0}, {2, 2}},
+	"12 23 14": {{2, 0}, {3, 0}, {2, 2}},
+	"12 23 21": {{3, 2}, {2, 2}, {4, 2}},
+	"12 23 22": {{2, 0}, {1, 2}, {4, 1}},
+	"12 23 23": {{2, 2}, {1, 2}, {1, 2}},
+	"12 23 24": {{0, 0}, {4, 0}, {1, 0}},
+	"12 23 31": {{4, 0}, {2, 0}, {3, 0}},
+	"12 23 32": {{2, 2}, {3, 0}, {4, 2}},
+	"12 23 33": {{2, 2}, {3, 0}, {5, 1}},
+	"12 23 34": {{2, 0}, {3, 0}, {4, 0}},
+	"12 23 41": {{2, 0}, {3, 0}, {1, 0}},
+	"12 23 42": {{2, 2}, {1, 2}, {4, 0}},
+	"12 23 43": {{2, 0}, {3, 0}, {5, 2}},
+	"12 23 44": {{2, 0}, {0, 0}, {4, 0}},
+	"12 23 45": {{3, 2}, {2, 2}, {4, 0}},
+	"12 31 11": {{2, 0}, {4, 0}, {1, 1}},
+	"12 31 12": {{2, 0}, {4, 0}, {2, 0}},
+	"12 31 13": {{2, 2}, {4, 2}, {3, 0}},
+	"12 31 14": {{2, 0}, {1, 0}, {4, 0}},
+	"12 31 21": {{2, 0}, {1, 0}, {3, 2}},
+	"12 31 22": {{2, 0}, {1, 0}, {4, 0}},
+	"12 31 23": {{4, 2}, {2, 2}, {3, 2}},
+	"12 31 24": {{2, 0}, {1, 0}, {3, 0}},
+	"12 31 31": {{1, 2}, {2, 2}, {2, 2}},
+	"12 31 32": {{4, 2}, {2, 2}, {2, 0}},
+	"12 31 33": {{2, 2}, {4, 0}, {4, 2}},
+	"12 31 34": {{1, 0}, {0, 0}, {4, 2}},
+	"12 31 41": {{4, 2}, {0, 0}, {5, 2}},
+	"12 31 42": {{4, 3}, {3, 0}, {2, 2}},
+	"12 31 43": {{3, 0}, {2, 0}, {1, 0}},
+	"12 31 44": {{2, 0}, {1, 0}, {0, 1}},
+	"12 31 45": {{2, 0}, {4, 0}, {1, 2}},
+	"12 32 11": {{2, 0}, {4, 0}, {1, 1}},
+	"12 32 12": {{2, 0}, {4, 2}, {2, 0}},
+	"12 32 13": {{2, 2}, {4, 0}, {2, 0}},
+	"12 32 14": {{3, 2}, {1, 0}, {3, 0}},
+	"12 32 21": {{2, 2}, {0, 0}, {3, 2}},
+	"12 32 22": {{2, 0}, {1, 2}, {4, 1}},
+	"12 32 23": {{4, 0}, {3, 2}, {2, 0}},
+	"12 32 24": {{4, 2}, {2, 0}, {3, 0}},
+	"12 32 31": {{2, 0}, {1, 2}, {1, 0}},
+	"12 32 32": {{3, 0}, {1, 2}, {1, 2}},
+	"12 32 33": {{2, 0}, {4, 2}, {5, 1}},
+	"12 32 34": {{5, 2}, {0, 0}, {2, 0}},
+	"12 32 41": {{3, 0}, {5, 2}, {2, 0}},
+	"12 32 42": {{5, 2}, {2, 2}, {3, 0}},
+	"12 32 43": {{3, 2}, {1, 0}, {0, 0}},
+	"12 32 44": {{2, 0}, {4, 2}, {2, 1}},
+	"12 32 45": {{4, 0}, {2, 0}, {1, 2}},
+	"12 33 11": {{2, 0}, {4, 0}, {1, 1}},
+	"12 33 12": {{4, 2}, {2, 1}, {4, 2}},
+	"12 33 13": {{2, 0}, {1, 1}, {2, 2}},
+	"12 33 14": {{2, 0}, {4, 1}, {2, 2}},
+	"12 33 21": {{2, 2}, {3, 1}, {1, 0}},
+	"12 33 22": {{2, 0}, {1, 2}, {2, 0}},
+	"12 33 23": {{2, 0}, {5, 1}, {3, 0}},
+	"12 33 24": {{4, 0}, {1, 2}, {2, 0}},
+	"12 33 31": {{2, 0}, {1, 1}, {1, 0}},
+	"12 33 32": {{4, 0}, {2, 0}, {2, 2}},
+	"12 33 33": {{2, 0}, {4, 0}, {1, 1}},
+	"12 33 34": {{2, 0}, {4, 1}, {4, 0}},
+	"12 33 41": {{2, 0}, {4, 1}, {1, 0}},
+	"12 33 42": {{2, 0}, {4, 0}, {1, 2}},
+	"12 33 43": {{4, 0}, {2, 1}, {3, 2}},
+	"12 33 44": {{4, 0}, {2, 1}, {3, 1}},
+	"12 33 45": {{5, 2}, {3, 1}, {2, 2}},
+	"12 34 11": {{2, 0}, {4, 0}, {1, 1}},
+	"12 34 12": {{2, 0}, {1, 2}, {2, 0}},
+	"12 34 13": {{2, 0}, {1, 2}, {2, 2}},
+	"12 34 14": {{2, 0}, {0, 0}, {2, 2}},
+	"12 34 15": {{2, 0}, {4, 0}, {2, 2}},
+	"12 34 21": {{2, 0}, {4, 0}, {3, 2}},
+	"12 34 22": {{2, 0}, {1, 2}, {4, 1}},
+	"12 34 23": {{4, 2}, {2, 2}, {3, 2}},
+	"12 34 24": {{4, 2}, {1, 0}, {3, 2}},
+	"12 34 25": {{4, 2}, {0, 0}, {3, 2}},
+	"12 34 31": {{3, 2}, {4, 0}, {4, 2}},
+	"12 34 32": {{2, 0}, {4, 0}, {4, 2}},
+	"12 34 33": {{2, 2}, {4, 0}, {4, 2}},
+	"12 34 34": {{2, 0}, {4, 0}, {4, 0}},
+	"12 34 35": {{4, 0}, {2, 0}, {2, 2}},
+	"12 34 41": {{2, 0}, {0, 0}, {1, 0}},
+	"12 34 42": {{4, 2}, {1, 0}, {2, 0}},
+	"12 34 43": {{4, 2}, {2, 2}, {1, 0}},
+	"12 34 44": {{2, 2}, {3, 0}, {5, 1}},
+	"12 34 45": {{2, 0}, {4, 0}, {1, 2}},
+	"12 34 51": {{3, 2}, {4, 0}, {1, 2}},
+	"12 34 52": {{3, 2}, {4, 0}, {1, 0}},
+	"12 34 53": {{2, 0}, {4, 0}, {0, 0}},
+	"12 34 54": {{2, 0}, {1, 2}, {4, 0}},
+	"12 34 55": {{4, 0}, {2, 0}, {1, 1}},
+}
@@ -0,0 +1,118 @@
+// Command bot plays Smash the Code, reading the referee's turns from stdin
+// and answering on stdout.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edwardadd/smash_the_code/config"
+	"github.com/edwardadd/smash_the_code/engine"
+	"github.com/edwardadd/smash_the_code/protocol"
+	"github.com/edwardadd/smash_the_code/replay"
+	"github.com/edwardadd/smash_the_code/search"
+)
+
+func main() {
+	var nextColours [engine.KNOWN_PAIRS][2]uint8
+	var playerGrid, cpuGrid engine.Grid
+
+	var replayPath, treeDir string
+	var treeOptions search.ExportOptions
+	settings, err := config.Parse(os.Args[0], os.Args[1:], func(flags *flag.FlagSet) {
+		flags.StringVar(&replayPath, "replay", "", "file to record each turn in")
+		flags.StringVar(&treeDir, "tree-dir", "", "directory to export each turn's search tree to, as DOT and JSON")
+		flags.IntVar(&treeOptions.Depth, "tree-depth", 3, "plies of the tree to export, 0 for all")
+		flags.IntVar(&treeOptions.TopK, "tree-top", 5, "best children exported per node, 0 for all")
+	})
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(2)
+	}
+
+	var recorder *replay.Writer = nil
+	if replayPath != "" {
+		file, err := os.Create(replayPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		recorder = replay.NewWriter(file)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	game := search.NewGame(settings)
+
+	for turn := 0; ; turn++ {
+		if err := protocol.ParseNextBlocks(reader, &nextColours); err != nil {
+			return
+		}
+
+		// Reading the pairs includes waiting for the referee, so timing starts after
+		parseStart := time.Now()
+		if err := protocol.ParseGrid(reader, &playerGrid); err != nil {
+			return
+		}
+		if err := protocol.ParseGrid(reader, &cpuGrid); err != nil {
+			return
+		}
+
+		parseElapsed := time.Since(parseStart)
+
+		move := game.PlayFrom(parseStart, &nextColours, &playerGrid, &cpuGrid)
+		protocol.Output(os.Stdout, move.Position, move.Rotation, move.Message)
+
+		stats := game.Stats()
+		stats.Parse = parseElapsed
+		fmt.Fprintln(os.Stderr, stats.String())
+
+		if treeDir != "" {
+			if err := exportTree(game, treeDir, turn, treeOptions); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+
+		if recorder != nil {
+			if err := recorder.Write(replay.NewTurn(turn, &nextColours, &playerGrid, &cpuGrid, move, stats)); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+}
+
+// exportTree writes the tree searched this turn to tree-<turn>.dot and
+// tree-<turn>.json in dir.
+func exportTree(game *search.Game, dir string, turn int, options search.ExportOptions) error {
+	root := game.ExportTree(options)
+
+	writers := map[string]func(io.Writer, *search.ExportedNode) error{
+		"dot":  search.WriteDOT,
+		"json": search.WriteJSON,
+	}
+
+	for extension, write := range writers {
+		file, err := os.Create(filepath.Join(dir, fmt.Sprintf("tree-%d.%s", turn, extension)))
+		if err != nil {
+			return err
+		}
+
+		err = write(file, root)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
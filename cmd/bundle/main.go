@@ -0,0 +1,418 @@
+// Command bundle merges a command and the packages of this module it imports
+// into a single main.go, as CodinGame only accepts one source file.
+//
+// Usage:
+//
+//	go run ./cmd/bundle [-o main.go] [./cmd/bot]
+//
+// Top level identifiers declared by more than one package are prefixed with
+// their package name, references to other packages lose their qualifier and
+// test files are left out.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrNoModule = errors.New("No module line in go.mod")
+
+var ErrDotImport = errors.New("Dot imports cannot be bundled")
+
+type bundledPackage struct {
+	path  string
+	files []*ast.File
+	types *types.Package
+	info  *types.Info
+}
+
+type bundler struct {
+	fset       *token.FileSet
+	root       string
+	modulePath string
+	standard   types.Importer
+	packages   map[string]*bundledPackage
+
+	// Dependencies come before the packages importing them
+	order []*bundledPackage
+
+	renamed map[string]bool
+}
+
+func newBundler(root string) (*bundler, error) {
+	modulePath, err := readModulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	return &bundler{
+		fset:       fset,
+		root:       root,
+		modulePath: modulePath,
+		standard:   importer.ForCompiler(fset, "source", nil),
+		packages:   map[string]*bundledPackage{},
+		renamed:    map[string]bool{},
+	}, nil
+}
+
+func readModulePath(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+
+	return "", ErrNoModule
+}
+
+func (b *bundler) isLocal(path string) bool {
+	return path == b.modulePath || strings.HasPrefix(path, b.modulePath+"/")
+}
+
+// Import type checks packages of this module from source and leaves the
+// rest to the standard library importer.
+func (b *bundler) Import(path string) (*types.Package, error) {
+	if !b.isLocal(path) {
+		return b.standard.Import(path)
+	}
+
+	p, err := b.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.types, nil
+}
+
+func (b *bundler) load(path string) (*bundledPackage, error) {
+	if p, ok := b.packages[path]; ok {
+		if p.types == nil {
+			return nil, fmt.Errorf("Import cycle through %s", path)
+		}
+		return p, nil
+	}
+
+	dir := filepath.Join(b.root, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(path, b.modulePath), "/")))
+	found, err := build.Default.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &bundledPackage{path: path}
+	b.packages[path] = p
+
+	for _, name := range found.GoFiles {
+		file, err := parser.ParseFile(b.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		p.files = append(p.files, file)
+	}
+
+	p.info = &types.Info{
+		Defs:   map[*ast.Ident]types.Object{},
+		Uses:   map[*ast.Ident]types.Object{},
+		Scopes: map[ast.Node]*types.Scope{},
+	}
+
+	config := types.Config{Importer: b}
+	p.types, err = config.Check(path, b.fset, p.files, p.info)
+	if err != nil {
+		return nil, err
+	}
+
+	b.order = append(b.order, p)
+	return p, nil
+}
+
+func (b *bundler) main() *bundledPackage {
+	return b.order[len(b.order)-1]
+}
+
+// name is what a top level identifier of p is called once bundled.
+func (b *bundler) name(p *bundledPackage, name string) string {
+	if p == b.main() || !b.renamed[name] {
+		return name
+	}
+
+	return p.types.Name() + "_" + name
+}
+
+// resolveClashes finds the top level identifiers that need their package
+// name as a prefix: those declared by several packages or by a package and
+// a standard library import, and those a local identifier would shadow once
+// the qualifier is gone.
+func (b *bundler) resolveClashes() {
+	declared := map[string]int{}
+	for _, p := range b.order {
+		for _, name := range p.types.Scope().Names() {
+			declared[name]++
+		}
+	}
+
+	imported := map[string]bool{}
+	for _, p := range b.order {
+		for _, imp := range p.types.Imports() {
+			if !b.isLocal(imp.Path()) {
+				imported[imp.Name()] = true
+			}
+		}
+		for _, file := range p.files {
+			for _, spec := range file.Imports {
+				if spec.Name != nil {
+					imported[spec.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	for name, count := range declared {
+		if name != "init" && name != "_" && (count > 1 || imported[name]) {
+			b.renamed[name] = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		b.eachQualified(func(p *bundledPackage, selector *ast.SelectorExpr, dep *bundledPackage) {
+			name := b.name(dep, selector.Sel.Name)
+			scope := p.types.Scope().Innermost(selector.Pos())
+			if scope == nil || scope == p.types.Scope() || b.renamed[selector.Sel.Name] {
+				return
+			}
+
+			if _, obj := scope.LookupParent(name, selector.Pos()); obj != nil && obj.Parent() != types.Universe {
+				b.renamed[selector.Sel.Name] = true
+				changed = true
+			}
+		})
+	}
+}
+
+// eachQualified calls visit for every reference to another package of this
+// module.
+func (b *bundler) eachQualified(visit func(p *bundledPackage, selector *ast.SelectorExpr, dep *bundledPackage)) {
+	for _, p := range b.order {
+		for _, file := range p.files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				selector, ok := node.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+
+				ident, ok := selector.X.(*ast.Ident)
+				if !ok {
+					return true
+				}
+
+				pkgName, ok := p.info.Uses[ident].(*types.PkgName)
+				if !ok || !b.isLocal(pkgName.Imported().Path()) {
+					return true
+				}
+
+				visit(p, selector, b.packages[pkgName.Imported().Path()])
+				return false
+			})
+		}
+	}
+}
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+// body returns the declarations of file with the identifiers renamed and
+// qualifiers dropped, and adds its standard library imports to imports.
+func (b *bundler) body(p *bundledPackage, file *ast.File, imports map[string]string) (string, error) {
+	filename := b.fset.Position(file.Pos()).Filename
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	offset := func(pos token.Pos) int {
+		return b.fset.Position(pos).Offset
+	}
+
+	var edits []edit
+
+	// Everything up to the last import goes, the bundle has its own header
+	var start int = offset(file.Name.End())
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			start = offset(gen.End())
+		}
+	}
+
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if b.isLocal(path) {
+			if spec.Name != nil && spec.Name.Name == "." {
+				return "", ErrDotImport
+			}
+			continue
+		}
+
+		var text string = spec.Path.Value
+		if spec.Name != nil {
+			text = spec.Name.Name + " " + text
+		}
+		imports[text] = path
+	}
+
+	renames := func(idents map[*ast.Ident]types.Object) {
+		for ident, obj := range idents {
+			if obj == nil || obj.Pkg() != p.types || obj.Parent() != p.types.Scope() {
+				continue
+			}
+
+			// The package's other files get their own edits
+			if ident.Pos() < file.FileStart || ident.Pos() >= file.FileEnd {
+				continue
+			}
+
+			if name := b.name(p, ident.Name); name != ident.Name && offset(ident.Pos()) >= start {
+				edits = append(edits, edit{offset(ident.Pos()), offset(ident.End()), name})
+			}
+		}
+	}
+	renames(p.info.Defs)
+	renames(p.info.Uses)
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		pkgName, ok := p.info.Uses[ident].(*types.PkgName)
+		if !ok || !b.isLocal(pkgName.Imported().Path()) {
+			return true
+		}
+
+		dep := b.packages[pkgName.Imported().Path()]
+		edits = append(edits, edit{offset(selector.Pos()), offset(selector.End()), b.name(dep, selector.Sel.Name)})
+		return false
+	})
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+
+	for _, e := range edits {
+		source = append(source[:e.start], append([]byte(e.text), source[e.end:]...)...)
+	}
+
+	return string(source[start:]), nil
+}
+
+// Bundle merges the command at path, relative to the module root, and its
+// dependencies in this module into a single formatted source file.
+func Bundle(root string, path string) ([]byte, error) {
+	b, err := newBundler(root)
+	if err != nil {
+		return nil, err
+	}
+
+	importPath := b.modulePath + "/" + strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+	p, err := b.load(strings.TrimSuffix(importPath, "/."))
+	if err != nil {
+		return nil, err
+	}
+
+	if p.types.Name() != "main" {
+		return nil, fmt.Errorf("%s is not a command", path)
+	}
+
+	b.resolveClashes()
+
+	var bodies bytes.Buffer
+	imports := map[string]string{}
+	for _, p := range b.order {
+		for _, file := range p.files {
+			body, err := b.body(p, file, imports)
+			if err != nil {
+				return nil, err
+			}
+
+			fmt.Fprintf(&bodies, "\n// From %s\n%s\n", b.fset.Position(file.Pos()).Filename[len(root)+1:], body)
+		}
+	}
+
+	var specs []string
+	for spec := range imports {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return imports[specs[i]] < imports[specs[j]]
+	})
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by cmd/bundle from %s. DO NOT EDIT.\n\npackage main\n\nimport (\n", path)
+	for _, spec := range specs {
+		fmt.Fprintf(&out, "\t%s\n", spec)
+	}
+	fmt.Fprintf(&out, ")\n%s", bodies.String())
+
+	return format.Source(out.Bytes())
+}
+
+func main() {
+	output := flag.String("o", "", "file to write, standard output if empty")
+	root := flag.String("root", ".", "module root")
+	flag.Parse()
+
+	var path string = "./cmd/bot"
+	if flag.NArg() > 0 {
+		path = flag.Arg(0)
+	}
+
+	absolute, err := filepath.Abs(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	source, err := Bundle(absolute, path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(source)
+		return
+	}
+
+	if err := os.WriteFile(*output, source, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
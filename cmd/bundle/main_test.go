@@ -0,0 +1,168 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func checkCompiles(t *testing.T, source []byte) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", source, 0)
+	if err != nil {
+		t.Fatalf("Bundle does not parse - %v\n%s", err, source)
+	}
+
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := config.Check("main", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("Bundle does not type check - %v\n%s", err, source)
+	}
+}
+
+func TestBundleBot(t *testing.T) {
+	source, err := Bundle(filepath.Join("..", ".."), "./cmd/bot")
+	if err != nil {
+		t.Fatalf("Bundle failed - %v", err)
+	}
+
+	checkCompiles(t, source)
+}
+
+func TestBundleRenamesClashes(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/clash\n\ngo 1.21\n",
+		"a/a.go": `package a
+
+const Size = 2
+
+func rand() int { return Size }
+
+func Value() int { return rand() }
+`,
+		"b/b.go": `package b
+
+import "example.com/clash/a"
+
+type Size int
+
+func Twice() Size {
+	Value := Size(3)
+	return Value * Size(a.Value())
+}
+`,
+		"b/b_test.go": "package b\n\nimport \"testing\"\n\nfunc TestNothing(t *testing.T) {}\n",
+		"cmd/c/main.go": `package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"example.com/clash/a"
+	"example.com/clash/b"
+)
+
+func main() {
+	fmt.Println(a.Size, b.Twice(), rand.Intn(2))
+}
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source, err := Bundle(root, "./cmd/c")
+	if err != nil {
+		t.Fatalf("Bundle failed - %v", err)
+	}
+
+	checkCompiles(t, source)
+
+	for _, expected := range []string{"a_Size", "b_Size", "a_rand", "a_Value"} {
+		if !strings.Contains(string(source), expected) {
+			t.Fatalf("Expected %s in the bundle\n%s", expected, source)
+		}
+	}
+
+	if strings.Contains(string(source), "TestNothing") {
+		t.Fatalf("Tests should be left out\n%s", source)
+	}
+}
+
+func TestBundleRenamesAcrossFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/spread\n\ngo 1.21\n",
+		"a/one.go": `package a
+
+func First() int { return helper() + limit }
+`,
+		"a/two.go": `package a
+
+const limit = 1
+
+func helper() int { return limit * 2 }
+
+func Second() int { return helper() }
+`,
+		"b/one.go": `package b
+
+func First() int { return helper() - limit }
+`,
+		"b/two.go": `package b
+
+const limit = 3
+
+func helper() int { return limit + 4 }
+`,
+		"cmd/c/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/spread/a"
+	"example.com/spread/b"
+)
+
+func main() {
+	fmt.Println(a.First(), a.Second(), b.First())
+}
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source, err := Bundle(root, "./cmd/c")
+	if err != nil {
+		t.Fatalf("Bundle failed - %v", err)
+	}
+
+	checkCompiles(t, source)
+
+	for _, expected := range []string{"a_helper", "b_helper", "a_limit", "b_limit", "a_First", "b_First"} {
+		if !strings.Contains(string(source), expected) {
+			t.Fatalf("Expected %s in the bundle\n%s", expected, source)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/edwardadd/smash_the_code/config"
+	"github.com/edwardadd/smash_the_code/engine"
+	"github.com/edwardadd/smash_the_code/notation"
+	"github.com/edwardadd/smash_the_code/replay"
+	"github.com/edwardadd/smash_the_code/search"
+)
+
+var ErrNoPosition = errors.New("Give a position with -position or -replay")
+
+var ErrNoTurn = errors.New("Turn is not in the replay")
+
+// analyse searches a position with the configured budget and prints every
+// legal choice with how the search rated it.
+func analyse(args []string) error {
+	var text, replayPath string
+	var turn int
+	settings, err := config.Parse("smash analyse", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&text, "position", "", "position to analyse, in notation")
+		flags.StringVar(&replayPath, "replay", "", "replay file to take the position from")
+		flags.IntVar(&turn, "turn", 0, "turn of the replay to analyse")
+	})
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	position, err := loadPosition(text, replayPath, turn)
+	if err != nil {
+		return err
+	}
+
+	// The ranking is the search's, so the move played must be too
+	settings.Book = false
+
+	game := search.NewGameAt(settings, position.Turn)
+	move := game.Play(&position.Pairs, &position.Player, &position.Opponent)
+
+	writeAnalysis(os.Stdout, &position, move, game.Analyse())
+	return nil
+}
+
+// loadPosition reads the position from its notation or from a turn of a
+// replay file.
+func loadPosition(text string, replayPath string, turn int) (notation.Position, error) {
+	if text != "" {
+		return notation.Parse(text)
+	}
+
+	if replayPath == "" {
+		return notation.Position{}, ErrNoPosition
+	}
+
+	file, err := os.Open(replayPath)
+	if err != nil {
+		return notation.Position{}, err
+	}
+	defer file.Close()
+
+	turns, err := replay.Read(file)
+	if err != nil {
+		return notation.Position{}, err
+	}
+
+	for _, recorded := range turns {
+		if recorded.Turn != turn {
+			continue
+		}
+
+		position := notation.Position{Pairs: recorded.NextColours, Turn: recorded.Turn}
+		if position.Player, err = engine.GridFromRows(recorded.PlayerGrid); err != nil {
+			return position, err
+		}
+		if position.Opponent, err = engine.GridFromRows(recorded.CpuGrid); err != nil {
+			return position, err
+		}
+		return position, nil
+	}
+
+	return notation.Position{}, ErrNoTurn
+}
+
+// writeAnalysis prints the choices best first, marking the one played.
+func writeAnalysis(w io.Writer, position *notation.Position, move search.Move, analysis []search.ChoiceAnalysis) {
+	sort.SliceStable(analysis, func(i, j int) bool {
+		if analysis[i].Lost != analysis[j].Lost {
+			return !analysis[i].Lost
+		}
+		return analysis[i].Max > analysis[j].Max
+	})
+
+	fmt.Fprintf(w, "%s\nplayed %d,%d\n\n", notation.Format(position), move.Position, move.Rotation)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "\tchoice\tmove\tvisits\tmean\tmax\tchain\t plan")
+	for _, choice := range analysis {
+		var marker string = ""
+		if choice.Position == move.Position && choice.Rotation == move.Rotation {
+			marker = "*"
+		}
+
+		stats := search.SearchStats{Principal: choice.Principal}
+		var plan string = stats.Plan(len(choice.Principal))
+		if choice.Duplicate {
+			position, rotation := engine.ChoiceToAction(choice.SameAs)
+			plan = fmt.Sprintf("same as %d,%d", position, rotation)
+		}
+
+		if choice.Visits == 0 {
+			fmt.Fprintf(table, "%s\t%d\t%d,%d\t0\t-\t-\t-\t %s\n", marker, choice.Choice, choice.Position, choice.Rotation, plan)
+			continue
+		}
+
+		var max string = fmt.Sprint(choice.Max)
+		if choice.Lost {
+			max = "lost"
+		}
+
+		fmt.Fprintf(table, "%s\t%d\t%d,%d\t%d\t%d\t%s\t%d\t %s\n", marker, choice.Choice, choice.Position, choice.Rotation,
+			choice.Visits, choice.Mean, max, choice.Chain, plan)
+	}
+	table.Flush()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/edwardadd/smash_the_code/book"
+	"github.com/edwardadd/smash_the_code/config"
+	"github.com/edwardadd/smash_the_code/engine"
+	"github.com/edwardadd/smash_the_code/search"
+)
+
+// Offline there is time for far more than a turn's samples and futures
+const (
+	BOOK_SAMPLES int = 10000
+	BOOK_FUTURES int = 8
+)
+
+// generateBook searches every pattern of the first pairs on an empty grid
+// and writes the placements found as the book's Go source.
+func generateBook(args []string) error {
+	var output string
+	var futures int
+	settings, err := config.Parse("smash book", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&output, "o", "book/openings.go", "file to write the book to")
+		flags.IntVar(&futures, "futures", BOOK_FUTURES, "sampled completions of the queue searched for each placement")
+
+		// Given before the arguments are parsed so -samples still wins
+		flags.Set("samples", strconv.Itoa(BOOK_SAMPLES))
+	})
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	// The book is what is being written, not what is played, and the same
+	// seed must write the same book however fast the machine
+	settings.Book = false
+	settings.Budget = 0
+
+	rng := rand.New(rand.NewSource(settings.Seed))
+	openings := map[string][]book.Move{}
+
+	patterns := book.Patterns(book.PAIRS)
+	for i, pattern := range patterns {
+		// Each future completes the queue past the pattern differently
+		queues := make([][][2]uint8, futures)
+		for f := range queues {
+			var sampled [engine.KNOWN_PAIRS][2]uint8
+			engine.SamplePairs(rng, &sampled)
+			queues[f] = append(append([][2]uint8{}, pattern...), sampled[:]...)
+		}
+
+		var grid, opponent engine.Grid
+		for c := range grid {
+			grid[c] = engine.EMPTY_SPACE
+		}
+		opponent = grid
+
+		var line []book.Move
+		for turn := 0; turn < book.PAIRS; turn++ {
+			move := vote(settings, queues, turn, &grid, &opponent)
+			if _, err := engine.Place(&grid, move.Position, move.Rotation, pattern[turn]); err != nil {
+				return err
+			}
+			line = append(line, move)
+		}
+
+		key := book.Key(pattern)
+		openings[key] = line
+		fmt.Fprintf(os.Stderr, "%d/%d %s %v\n", i+1, len(patterns), key, line)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	err = book.Write(file, "smash book", openings)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// vote searches the turn once for each future and returns the placement
+// chosen most often, the earliest found on a tie. A search that would fire
+// a chain shorter than the target votes for its best placement that does
+// not, as a book line has no reason to waste its blocks.
+func vote(settings search.Config, queues [][][2]uint8, turn int, grid *engine.Grid, opponent *engine.Grid) book.Move {
+	var counts = map[book.Move]int{}
+	var order []book.Move
+
+	for _, queue := range queues {
+		var next [engine.KNOWN_PAIRS][2]uint8
+		copy(next[:], queue[turn:])
+
+		game := search.NewGame(settings)
+		game.Play(&next, grid, opponent)
+
+		analysis := game.Analyse()
+		sort.SliceStable(analysis, func(i, j int) bool {
+			if analysis[i].Lost != analysis[j].Lost {
+				return !analysis[i].Lost
+			}
+			return analysis[i].Max > analysis[j].Max
+		})
+
+		var move book.Move = book.Move{Position: analysis[0].Position, Rotation: analysis[0].Rotation}
+		for _, choice := range analysis {
+			if choice.Visits == 0 || firesShort(grid, choice.Position, choice.Rotation, next[0], settings.Timing.TargetChain) {
+				continue
+			}
+
+			move = book.Move{Position: choice.Position, Rotation: choice.Rotation}
+			break
+		}
+
+		if counts[move] == 0 {
+			order = append(order, move)
+		}
+		counts[move]++
+	}
+
+	var best book.Move = order[0]
+	for _, move := range order {
+		if counts[move] > counts[best] {
+			best = move
+		}
+	}
+	return best
+}
+
+// firesShort reports whether the placement sets off a chain shorter than
+// target.
+func firesShort(grid *engine.Grid, position int, rotation int, colours [2]uint8, target int) bool {
+	var placed engine.Grid = *grid
+	resolution, err := engine.Place(&placed, position, rotation, colours)
+	return err == nil && resolution.ChainCount > 0 && resolution.ChainCount < target
+}
@@ -0,0 +1,53 @@
+// Command smash holds the offline tools for studying positions, away from
+// the referee.
+//
+// Usage:
+//
+//	go run ./cmd/smash <command> [flags]
+//
+// The commands are:
+//
+//	analyse   search a position and rank every legal move
+//	book      generate the opening book
+//	solve     find the best line for a fixed sequence of pairs
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+var commands = map[string]func(args []string) error{
+	"analyse": analyse,
+	"book":    generateBook,
+	"solve":   solve,
+}
+
+func usage() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(os.Stderr, "usage: smash <command> [flags]\n\ncommands: %v\n", names)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
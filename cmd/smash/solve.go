@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/edwardadd/smash_the_code/engine"
+	"github.com/edwardadd/smash_the_code/notation"
+	"github.com/edwardadd/smash_the_code/solver"
+)
+
+var ErrBadObjective = errors.New("Objective must be chain or score")
+
+// solve finds the best line for the player's grid and the first pairs of a
+// position.
+func solve(args []string) error {
+	flags := flag.NewFlagSet("smash solve", flag.ContinueOnError)
+	text := flags.String("position", "", "position to solve, in notation")
+	plies := flags.Int("plies", 4, "pairs of the queue to place")
+	objective := flags.String("objective", "chain", "chain for the longest chain, score for the official score")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	var goal solver.Objective
+	switch *objective {
+	case "chain":
+		goal = solver.CHAIN
+	case "score":
+		goal = solver.SCORE
+	default:
+		return ErrBadObjective
+	}
+
+	if *text == "" {
+		return ErrNoPosition
+	}
+
+	position, err := notation.Parse(*text)
+	if err != nil {
+		return err
+	}
+
+	if *plies < 1 || *plies > engine.KNOWN_PAIRS {
+		return fmt.Errorf("Plies must be between 1 and %d", engine.KNOWN_PAIRS)
+	}
+
+	start := time.Now()
+	solution := solver.Solve(&position.Player, position.Pairs[:*plies], goal)
+
+	fmt.Printf("%s %d in %v, %d nodes, %d pruned\n", *objective, solution.Value, time.Since(start).Round(time.Millisecond), solution.Nodes, solution.Pruned)
+	for i, placement := range solution.Line {
+		fmt.Fprintf(os.Stdout, "%d. %s%s %d,%d", i+1, string(notation.LETTERS[position.Pairs[i][0]]), string(notation.LETTERS[position.Pairs[i][1]]), placement.Position, placement.Rotation)
+		if placement.ChainCount > 0 {
+			fmt.Printf(" chain x%d for %d", placement.ChainCount, placement.Score)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
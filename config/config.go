@@ -0,0 +1,83 @@
+// Package config builds a search configuration from the defaults, an
+// optional JSON file and command line flags, in that order of precedence.
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/edwardadd/smash_the_code/search"
+)
+
+// Read overlays the JSON in r onto config, leaving anything it does not
+// mention untouched.
+func Read(r io.Reader, config *search.Config) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(config)
+}
+
+// Load overlays the JSON file at path onto config.
+func Load(path string, config *search.Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return Read(file, config)
+}
+
+// Register adds flags for the main search parameters to flags, writing into
+// config when they are parsed.
+func Register(flags *flag.FlagSet, config *search.Config) {
+	flags.IntVar(&config.Samples, "samples", config.Samples, "rollouts per turn")
+	flags.IntVar(&config.Depth, "depth", config.Depth, "plies searched ahead")
+	flags.IntVar(&config.Determinizations, "determinizations", config.Determinizations, "sampled futures per leaf past the known pairs")
+	flags.Int64Var(&config.Seed, "seed", config.Seed, "random seed")
+	flags.IntVar(&config.Budget, "budget", config.Budget, "milliseconds into the turn to stop sampling at, 0 for no limit")
+	flags.IntVar(&config.Timing.TargetChain, "target-chain", config.Timing.TargetChain, "chain length to build towards")
+	flags.IntVar(&config.Timing.SkullChain, "skull-chain", config.Timing.SkullChain, "chain length to fire once skulls land")
+	flags.IntVar(&config.Timing.ThreatChain, "threat-chain", config.Timing.ThreatChain, "opponent chain length that makes us fire early")
+	flags.IntVar(&config.PlanPlies, "plan", config.PlanPlies, "plies of the planned line to show as the move's message")
+	flags.BoolVar(&config.Book, "book", config.Book, "play the opening book while the game follows it")
+}
+
+// Parse returns the configuration for the command line args. A -config file
+// is applied over the defaults and any other flags over the file. extra, if
+// given, registers the command's own flags.
+func Parse(name string, args []string, extra func(flags *flag.FlagSet)) (search.Config, error) {
+	config := search.DefaultConfig()
+
+	var path string
+	flags := newFlagSet(name, &path, &config, extra)
+	if err := flags.Parse(args); err != nil {
+		return config, err
+	}
+
+	if path == "" {
+		return config, nil
+	}
+
+	// Start again from the file so the flags can override it
+	config = search.DefaultConfig()
+	if err := Load(path, &config); err != nil {
+		return config, err
+	}
+
+	flags = newFlagSet(name, &path, &config, extra)
+	err := flags.Parse(args)
+	return config, err
+}
+
+func newFlagSet(name string, path *string, config *search.Config, extra func(flags *flag.FlagSet)) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(path, "config", "", "JSON file overriding the default configuration")
+	Register(flags, config)
+	if extra != nil {
+		extra(flags)
+	}
+	return flags
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edwardadd/smash_the_code/search"
+)
+
+func TestReadOverridesOnlyGivenFields(t *testing.T) {
+	config := search.DefaultConfig()
+	err := Read(strings.NewReader(`{"samples": 50, "weights": {"height": 7}}`), &config)
+	if err != nil {
+		t.Fatalf("Read failed - %v", err)
+	}
+
+	expected := search.DefaultConfig()
+	expected.Samples = 50
+	expected.Weights.Height = 7
+	if config != expected {
+		t.Fatalf("Got %+v, expected %+v", config, expected)
+	}
+
+	if err := Read(strings.NewReader(`{"sample": 50}`), &config); err == nil {
+		t.Fatalf("Unknown fields should be rejected")
+	}
+}
+
+func TestParseFlagsOverrideFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot.json")
+	if err := os.WriteFile(path, []byte(`{"samples": 50, "depth": 3}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Parse("bot", []string{"-depth", "6", "-config", path, "-seed", "9"}, nil)
+	if err != nil {
+		t.Fatalf("Parse failed - %v", err)
+	}
+
+	if config.Samples != 50 || config.Depth != 6 || config.Seed != 9 {
+		t.Fatalf("Got samples %d depth %d seed %d", config.Samples, config.Depth, config.Seed)
+	}
+
+	config, err = Parse("bot", nil, nil)
+	if err != nil || config != search.DefaultConfig() {
+		t.Fatalf("No arguments should give the defaults, got %+v %v", config, err)
+	}
+}
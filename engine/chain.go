@@ -0,0 +1,263 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FindConnectedBlocks returns the colour and size of the group holding the
+// block at (x, y), marking it in visited. Groups of four or more are cleared
+// from the grid along with the skulls they touch, which are also returned.
+func FindConnectedBlocks(grid *Grid, x int, y int, visited *Grid) (uint8, int, int) {
+	if x < 0 || x >= GRID_WIDTH || y < 0 || y >= GRID_HEIGHT {
+		return EMPTY_SPACE, 0, 0
+	}
+
+	initialIndex := x + y*GRID_WIDTH
+	if grid[initialIndex] == EMPTY_SPACE || grid[initialIndex] == 0 {
+		return EMPTY_SPACE, 0, 0
+	}
+
+	var seen Mask
+	component := fill(grid, initialIndex, &seen)
+	if component.Size <= 3 {
+		for i := 0; i < GRID_WIDTH*GRID_HEIGHT; i++ {
+			if component.Members.Has(i) {
+				visited[i] = 1
+			}
+		}
+		return component.Colour, component.Size, 0
+	}
+
+	cleared := component.Members.Or(component.Skulls)
+	for i := 0; i < GRID_WIDTH*GRID_HEIGHT; i++ {
+		if cleared.Has(i) {
+			grid[i] = EMPTY_SPACE
+			visited[i] = 1
+		}
+	}
+
+	return component.Colour, component.Size, component.Skulls.Count()
+}
+
+func GroupBonus(blocks int) int {
+	var score int = blocks - 4
+	if score < 0 {
+		return 0
+	}
+
+	// 10 blocks are worth 6, anything larger jumps to 8
+	if blocks >= 11 {
+		return 8
+	}
+
+	return score
+}
+
+func ColourBonus(colours int) int {
+	var score int = 1
+	if colours <= 1 {
+		return 0
+	}
+
+	for i := 1; i < colours && i < 5; i++ {
+		score *= 2
+	}
+	return score
+}
+
+func ChainPowerForStep(step int) int {
+	// CP is the chain power, starting at 0 for the first step.
+	// It is worth 8 for the second step and for each following step it is worth twice as much as the previous step.
+
+	var chainPower int = 0
+	for i := 0; i < step; i++ {
+		if i == 1 {
+			chainPower = 8
+		} else if i > 1 {
+			chainPower *= 2
+		}
+	}
+
+	return chainPower
+}
+
+// Group is a connected group of one colour cleared during a chain step.
+type Group struct {
+	Colour uint8
+	Size   int
+}
+
+// ChainStep is one step of a chain: the groups cleared together, the skulls
+// they took with them, how many blocks fell afterwards and the grid left.
+type ChainStep struct {
+	Step    int
+	Groups  []Group
+	Skulls  int
+	Dropped int
+	Grid    Grid
+
+	// Points the step scores in the official rules
+	Score int
+}
+
+// Blocks is the number of coloured blocks the step cleared.
+func (step *ChainStep) Blocks() int {
+	var blocks int = 0
+	for _, group := range step.Groups {
+		blocks += group.Size
+	}
+	return blocks
+}
+
+// Colours is the number of different colours the step cleared.
+func (step *ChainStep) Colours() int {
+	var seen [6]bool
+	var colours int = 0
+	for _, group := range step.Groups {
+		if !seen[group.Colour] {
+			seen[group.Colour] = true
+			colours++
+		}
+	}
+	return colours
+}
+
+func (step ChainStep) String() string {
+	var groups []string
+	for _, group := range step.Groups {
+		groups = append(groups, fmt.Sprintf("%d %s", group.Size, ColourString[group.Colour]))
+	}
+
+	return fmt.Sprintf("step %d: cleared %s, %d skulls, %d dropped", step.Step, strings.Join(groups, ", "), step.Skulls, step.Dropped)
+}
+
+// Resolution sums up what happened while the chains set off by a pair
+// resolved.
+type Resolution struct {
+	ChainCount int
+
+	// Blocks and skulls that were cleared
+	ClearedBlocks int
+	ClearedSkulls int
+
+	// Connected groups seen along the way, the ones of exactly three and
+	// the blocks they held
+	Groups        int
+	GroupsOfThree int
+	GroupedBlocks int
+
+	// Each step of the chain in order, empty if nothing cleared
+	Steps []ChainStep
+
+	// Points the whole chain scores in the official rules
+	Score int
+}
+
+// ResolveChains clears every group the pair dropped at (leftX, leftY) and
+// (rightX, rightY) sets off, letting the grid fall after each step until
+// nothing more clears.
+func ResolveChains(grid *Grid, leftX int, leftY int, rightX int, rightY int) Resolution {
+	var resolution Resolution
+	var step ChainStep
+	var cleared Mask
+
+	const blocksMakeClear int = 4
+
+	// found records a group found while scanning for the current step
+	found := func(component *Component) {
+		resolution.Groups++
+		resolution.GroupedBlocks += component.Size
+
+		if component.Size >= blocksMakeClear {
+			step.Groups = append(step.Groups, Group{component.Colour, component.Size})
+			cleared = cleared.Or(component.Members).Or(component.Skulls)
+		}
+	}
+
+	//check for clearing at recently dropped position
+	var placed Mask
+	for _, cell := range [2][2]int{{leftX, leftY}, {rightX, rightY}} {
+		if cell[0] < 0 || cell[0] >= GRID_WIDTH || cell[1] < 0 || cell[1] >= GRID_HEIGHT {
+			continue
+		}
+
+		index := cell[0] + cell[1]*GRID_WIDTH
+		if grid[index] > 0 && grid[index] <= 5 && !placed.Has(index) {
+			component := fill(grid, index, &placed)
+			found(&component)
+		}
+	}
+
+	// Most placements clear nothing, so only then label the whole grid
+	if len(step.Groups) == 0 {
+		return resolution
+	}
+
+	components := Components(grid)
+
+	for {
+		//find connected blocks and continue
+		for i := range components {
+			if resolution.ChainCount == 0 && components[i].Members.Intersects(placed) {
+				continue
+			}
+
+			if components[i].Size == 3 {
+				resolution.GroupsOfThree++
+			}
+			found(&components[i])
+		}
+
+		if len(step.Groups) == 0 {
+			break
+		}
+
+		for i := 0; i < GRID_WIDTH*GRID_HEIGHT; i++ {
+			if cleared.Has(i) {
+				if grid[i] == 0 {
+					step.Skulls++
+				}
+				grid[i] = EMPTY_SPACE
+			}
+		}
+
+		resolution.ChainCount++
+		step.Step = resolution.ChainCount
+		step.Dropped = grid.ApplyGravity()
+		step.Grid = *grid
+
+		step.Score = StepScore(&step)
+
+		resolution.ClearedBlocks += step.Blocks()
+		resolution.ClearedSkulls += step.Skulls
+		resolution.Score += step.Score
+		resolution.Steps = append(resolution.Steps, step)
+
+		step = ChainStep{}
+		cleared = Mask{}
+		components = Components(grid)
+	}
+
+	return resolution
+}
+
+// StepScore is what a chain step scores in the official rules: ten points
+// per coloured block cleared, times the chain power, colour bonus and group
+// bonuses added together and kept between 1 and 999.
+func StepScore(step *ChainStep) int {
+	var multiplier int = ChainPowerForStep(step.Step) + ColourBonus(step.Colours())
+	for _, group := range step.Groups {
+		multiplier += GroupBonus(group.Size)
+	}
+
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	if multiplier > 999 {
+		multiplier = 999
+	}
+
+	return 10 * step.Blocks() * multiplier
+}
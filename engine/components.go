@@ -0,0 +1,102 @@
+package engine
+
+import "math/bits"
+
+// Mask is a set of cells of the grid, one bit per index.
+type Mask [2]uint64
+
+func (mask *Mask) Set(index int) {
+	mask[index/64] |= 1 << uint(index%64)
+}
+
+func (mask Mask) Has(index int) bool {
+	return mask[index/64]&(1<<uint(index%64)) != 0
+}
+
+func (mask Mask) Count() int {
+	return bits.OnesCount64(mask[0]) + bits.OnesCount64(mask[1])
+}
+
+func (mask Mask) Or(other Mask) Mask {
+	return Mask{mask[0] | other[0], mask[1] | other[1]}
+}
+
+func (mask Mask) Intersects(other Mask) bool {
+	return mask[0]&other[0] != 0 || mask[1]&other[1] != 0
+}
+
+// Component is a connected group of blocks of one colour.
+type Component struct {
+	Colour uint8
+	Size   int
+
+	// Cells of the group and the skulls touching it, which are cleared
+	// along with it
+	Members Mask
+	Skulls  Mask
+}
+
+// Components labels every connected group of coloured blocks in the grid,
+// in the order of their first cell. The grid is left untouched.
+func Components(grid *Grid) []Component {
+	var components []Component = make([]Component, 0, 16)
+	var seen Mask
+
+	for i := 0; i < GRID_WIDTH*GRID_HEIGHT; i++ {
+		if grid[i] > 0 && grid[i] <= 5 && !seen.Has(i) {
+			components = append(components, fill(grid, i, &seen))
+		}
+	}
+
+	return components
+}
+
+// fill walks the group holding the block at index, adding it to seen.
+func fill(grid *Grid, index int, seen *Mask) Component {
+	var stack [GRID_WIDTH * GRID_HEIGHT]int
+	var si int = 0
+	var component Component = Component{Colour: grid[index]}
+
+	stack[si] = index
+	si++
+	seen.Set(index)
+
+	for si > 0 {
+		si--
+		index := stack[si]
+		component.Members.Set(index)
+		component.Size++
+
+		var x int = index % GRID_WIDTH
+		var y int = index / GRID_WIDTH
+		var neighbours [4]int = [4]int{-1, -1, -1, -1}
+		if x > 0 {
+			neighbours[0] = index - 1
+		}
+		if x < GRID_WIDTH-1 {
+			neighbours[1] = index + 1
+		}
+		if y > 0 {
+			neighbours[2] = index - GRID_WIDTH
+		}
+		if y < GRID_HEIGHT-1 {
+			neighbours[3] = index + GRID_WIDTH
+		}
+
+		for _, neighbour := range neighbours {
+			if neighbour < 0 {
+				continue
+			}
+
+			if grid[neighbour] == 0 {
+				component.Skulls.Set(neighbour)
+			} else if grid[neighbour] == component.Colour && !seen.Has(neighbour) {
+				seen.Set(neighbour)
+				stack[si] = neighbour
+				si++
+			}
+		}
+	}
+
+	return component
+}
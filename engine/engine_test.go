@@ -0,0 +1,1208 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestFloodFill(t *testing.T) {
+	var grid Grid = Grid{
+		1, 1, 1, 1, 1, 1,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+	var visited Grid
+	colour, count, _ := FindConnectedBlocks(&grid, 0, 0, &visited)
+
+	if colour != 1 {
+		t.Fatalf("Incorrect colour")
+	}
+
+	if count != 6 {
+		t.Fatalf("Incorrect number of blocks found")
+	}
+}
+
+func TestFloodFillWithSkulls(t *testing.T) {
+	var grid Grid = Grid{
+		0, 0, 0, 0, 0, 0,
+		0, 1, 1, 1, 1, 1,
+		0, 0, 0, 0, 0, 0,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+	var exp Grid = Grid{
+		0, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		0, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+	var visited Grid
+	colour, count, _ := FindConnectedBlocks(&grid, 1, 1, &visited)
+
+	if colour != 1 {
+		t.Fatalf("Incorrect colour")
+	}
+
+	if count != 5 {
+		t.Fatalf("Incorrect number of blocks found")
+	}
+
+	if grid != exp {
+		t.Fatalf("Grids do not match\nResult\n%v\n%v\n", grid, exp)
+	}
+}
+
+func TestGravity(t *testing.T) {
+	var grid Grid = Grid{
+		1, 1, 1, 1, 1, 1,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+	var expectedGrid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		1, 1, 1, 1, 1, 1,
+	}
+
+	grid.ApplyGravity()
+
+	if grid != expectedGrid {
+		t.Fatalf("Unexpected grid\n%v\n%v\n", grid, expectedGrid)
+	}
+}
+
+func BenchmarkFloodFill1(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var visited Grid
+		var grid Grid = Grid{
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			1, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		}
+		FindConnectedBlocks(&grid, 0, 11, &visited)
+	}
+}
+
+func BenchmarkFloodFillFull(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var visited Grid
+		var grid Grid = Grid{
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1,
+		}
+		FindConnectedBlocks(&grid, 0, 11, &visited)
+	}
+}
+
+func TestFloodFill1(t *testing.T) {
+	var visited Grid
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		1, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+	colour, count, _ := FindConnectedBlocks(&grid, 0, 11, &visited)
+
+	if colour != 1 {
+		t.Fatalf("Wrong colour - %d, expected %d", colour, 1)
+	}
+
+	if count != 1 {
+		t.Fatalf("Wrong count - %d, expected %d", count, 1)
+	}
+
+}
+
+func TestFloodFillFull(t *testing.T) {
+	var visited Grid
+	var grid Grid = Grid{
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1,
+	}
+	colour, count, _ := FindConnectedBlocks(&grid, 0, 0, &visited)
+
+	if colour != 1 {
+		t.Fatalf("Wrong colour - %d, expected %d", colour, 1)
+	}
+
+	if count != GRID_WIDTH*GRID_HEIGHT {
+		t.Fatalf("Wrong count - %d, expected %d", count, 1)
+	}
+}
+
+func TestPositionBlockInGrid(t *testing.T) {
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+	var exp []Grid = []Grid{
+		{
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			4, 5, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		},
+		{
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			4, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+			5, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		},
+	}
+
+	var rotation []int = []int{0, 3}
+	var leftX []int = []int{0, 0}
+	var rightX []int = []int{1, 0}
+	var leftY []int = []int{11, 11}
+	var rightY []int = []int{11, 11}
+
+	var expY0 []int = []int{GRID_HEIGHT - 1, GRID_HEIGHT - 2}
+	var expY1 []int = []int{GRID_HEIGHT - 1, GRID_HEIGHT - 1}
+
+	for i := 0; i < 2; i++ {
+		var tempGrid Grid = grid
+		y0, y1 := PositionBlockInGridWithY(&tempGrid, leftX[i], rightX[i], rotation[i], 4, 5, leftY[i], rightY[i])
+
+		if tempGrid != exp[i] {
+			tempGrid.Print("Result")
+			exp[i].Print("Expected")
+			t.Fatalf("%d, Invalid Grid\n", i)
+		}
+
+		if y0 != expY0[i] {
+			t.Fatalf("y0 wrong")
+		}
+
+		if y1 != expY1[i] {
+			t.Fatalf("y1 wrong")
+		}
+	}
+}
+
+func TestGenerateMoves(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+
+	if moves := GenerateMoves(&grid, [2]uint8{1, 2}); len(moves) != 22 {
+		t.Fatalf("Expected all 22 choices for a mixed pair, got %d", len(moves))
+	}
+
+	// 5 horizontal and 6 vertical placements remain once mirrored rotations collapse
+	moves := GenerateMoves(&grid, [2]uint8{3, 3})
+	if len(moves) != 11 {
+		t.Fatalf("Expected 11 distinct choices for a same colour pair, got %d", len(moves))
+	}
+
+	// Leave a single space in the first column
+	for y := 1; y < GRID_HEIGHT; y++ {
+		grid[y*GRID_WIDTH] = 4
+	}
+
+	for _, choice := range GenerateMoves(&grid, [2]uint8{1, 2}) {
+		position, rotation := ChoiceToAction(choice)
+		leftX, _ := PairColumns(position, rotation)
+
+		if leftX == 0 && (rotation == 1 || rotation == 3) {
+			t.Fatalf("Choice %d stacks a pair into a column with one space", choice)
+		}
+	}
+}
+
+func TestCheckPlacement(t *testing.T) {
+	var empty Grid
+	for i := range empty {
+		empty[i] = EMPTY_SPACE
+	}
+
+	// Column 1 filled to the top, column 4 with a single space left
+	var walled Grid = empty
+	for y := 0; y < GRID_HEIGHT; y++ {
+		walled[1+y*GRID_WIDTH] = 1
+	}
+	for y := 1; y < GRID_HEIGHT; y++ {
+		walled[4+y*GRID_WIDTH] = 2
+	}
+
+	var spawnBlocked Grid = empty
+	for y := 0; y < GRID_HEIGHT; y++ {
+		spawnBlocked[SPAWN_COLUMN+y*GRID_WIDTH] = 3
+	}
+
+	var tests = []struct {
+		grid     *Grid
+		position int
+		rotation int
+		err      error
+	}{
+		{&empty, 2, 0, nil},
+		{&empty, 0, 1, nil},
+		{&empty, 5, 2, nil},
+		{&empty, 2, 4, ErrInvalidChoice},
+		{&empty, -1, 1, ErrInvalidChoice},
+		{&empty, 0, 2, ErrInvalidChoice},
+		{&empty, 5, 0, ErrInvalidChoice},
+		{&walled, 0, 1, ErrPathBlocked},
+		{&walled, 1, 0, ErrNoMoreSpace},
+		{&walled, 2, 1, nil},
+		{&walled, 4, 0, nil},
+		{&walled, 4, 1, ErrNoMoreSpace},
+		{&walled, 5, 1, nil},
+		{&spawnBlocked, 5, 1, ErrSpawnBlocked},
+	}
+
+	for i, test := range tests {
+		err := CheckPlacement(test.grid, test.position, test.rotation)
+		if err != test.err {
+			t.Fatalf("%d: position %d rotation %d, got %v expected %v", i, test.position, test.rotation, err, test.err)
+		}
+	}
+}
+
+func TestSamplePairs(t *testing.T) {
+	var pairs [8][2]uint8
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		SamplePairs(rng, &pairs)
+		for _, pair := range pairs {
+			if pair[0] < 1 || pair[0] > 5 || pair[1] < 1 || pair[1] > 5 {
+				t.Fatalf("Sampled colour out of range %v", pair)
+			}
+		}
+	}
+}
+
+func TestResolveChainsSteps(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+
+	at := func(x int, y int) *uint8 {
+		return &grid[x+y*GRID_WIDTH]
+	}
+
+	*at(0, 11), *at(0, 10), *at(0, 9) = 1, 1, 1
+	*at(1, 11), *at(1, 10), *at(1, 9) = 2, 2, 2
+	*at(1, 8) = 0
+
+	// A vertical pair completes the ones, the two falls onto the twos
+	*at(0, 8), *at(0, 7) = 1, 2
+
+	resolution := ResolveChains(&grid, 0, 8, 0, 7)
+
+	if resolution.ChainCount != 2 || len(resolution.Steps) != 2 {
+		t.Fatalf("Expected 2 steps, got %d %v", resolution.ChainCount, resolution.Steps)
+	}
+
+	first, second := resolution.Steps[0], resolution.Steps[1]
+	if first.Step != 1 || len(first.Groups) != 1 || first.Groups[0] != (Group{1, 4}) || first.Skulls != 1 || first.Dropped != 1 {
+		t.Fatalf("Unexpected first step %v", first)
+	}
+
+	if first.Grid[0+11*GRID_WIDTH] != 2 || first.Grid[1+8*GRID_WIDTH] != EMPTY_SPACE {
+		t.Fatalf("First snapshot should have the two fallen and the skull gone")
+	}
+
+	if second.Step != 2 || len(second.Groups) != 1 || second.Groups[0] != (Group{2, 4}) || second.Skulls != 0 || second.Dropped != 0 {
+		t.Fatalf("Unexpected second step %v", second)
+	}
+
+	var empty Grid
+	for i := range empty {
+		empty[i] = EMPTY_SPACE
+	}
+	if second.Grid != empty || grid != empty {
+		t.Fatalf("Chain should clear the whole grid")
+	}
+
+	if resolution.ClearedBlocks != first.Blocks()+second.Blocks() || resolution.ClearedSkulls != 1 {
+		t.Fatalf("Totals should add up the steps, got %d blocks %d skulls", resolution.ClearedBlocks, resolution.ClearedSkulls)
+	}
+
+	if first.String() != "step 1: cleared 4 Blue, 1 skulls, 1 dropped" {
+		t.Fatalf("Unexpected description %q", first.String())
+	}
+}
+
+func TestResolveChainsNothingCleared(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	grid[0+11*GRID_WIDTH], grid[1+11*GRID_WIDTH] = 1, 1
+
+	before := grid
+	resolution := ResolveChains(&grid, 0, 11, 1, 11)
+	if resolution.ChainCount != 0 || len(resolution.Steps) != 0 || grid != before {
+		t.Fatalf("Nothing should clear, got %+v", resolution)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	var cases = []struct {
+		name     string
+		rows     []string
+		colours  [2]uint8
+		position int
+		rotation int
+		final    []string
+		chains   int
+		score    int
+	}{
+		{
+			name:     "single group",
+			rows:     []string{"11...."},
+			colours:  [2]uint8{1, 1},
+			position: 2,
+			rotation: 0,
+			final:    nil,
+			chains:   1,
+			score:    40,
+		},
+		{
+			name:     "two colours at once",
+			rows:     []string{"22.2..", "11.1.."},
+			colours:  [2]uint8{1, 2},
+			position: 2,
+			rotation: 1,
+			final:    nil,
+			chains:   1,
+			score:    160,
+		},
+		{
+			name:     "skull between two groups",
+			rows:     []string{"1.2...", "1.2...", "102..."},
+			colours:  [2]uint8{1, 2},
+			position: 0,
+			rotation: 0,
+			final:    nil,
+			chains:   1,
+			score:    160,
+		},
+		{
+			name:     "skulls in corners",
+			rows:     []string{".....0", "0111.0"},
+			colours:  [2]uint8{1, 3},
+			position: 4,
+			rotation: 1,
+			final:    []string{"....30"},
+			chains:   1,
+			score:    40,
+		},
+		{
+			name:     "cascade through a skull",
+			rows:     []string{"1..2.2", "11.022"},
+			colours:  [2]uint8{1, 4},
+			position: 2,
+			rotation: 1,
+			final:    []string{"..4..."},
+			chains:   2,
+			score:    360,
+		},
+		{
+			name:     "pair pointing left",
+			rows:     []string{".1....", ".1....", ".1....", ".4...."},
+			colours:  [2]uint8{1, 3},
+			position: 1,
+			rotation: 2,
+			final:    []string{"34...."},
+			chains:   1,
+			score:    40,
+		},
+		{
+			name:     "group bonus",
+			rows:     []string{"11.111"},
+			colours:  [2]uint8{1, 1},
+			position: 2,
+			rotation: 1,
+			final:    nil,
+			chains:   1,
+			score:    210,
+		},
+		{
+			name:     "nothing cleared",
+			rows:     []string{"12...."},
+			colours:  [2]uint8{3, 4},
+			position: 2,
+			rotation: 3,
+			final:    []string{"..3...", "124..."},
+			chains:   0,
+			score:    0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			grid, err := GridFromRows(c.rows)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			expected, err := GridFromRows(c.final)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			resolution, err := Place(&grid, c.position, c.rotation, c.colours)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if grid != expected {
+				t.Fatalf("Grid is\n%s\nexpected\n%s", strings.Join(grid.Rows(), "\n"), strings.Join(expected.Rows(), "\n"))
+			}
+
+			if resolution.ChainCount != c.chains {
+				t.Fatalf("Chain count is %d, expected %d", resolution.ChainCount, c.chains)
+			}
+
+			if resolution.Score != c.score {
+				t.Fatalf("Score is %d, expected %d", resolution.Score, c.score)
+			}
+		})
+	}
+}
+
+func TestBonuses(t *testing.T) {
+	if ColourBonus(1) != 0 || ColourBonus(2) != 2 || ColourBonus(5) != 16 {
+		t.Fatalf("Incorrect colour bonus")
+	}
+
+	if GroupBonus(4) != 0 || GroupBonus(10) != 6 || GroupBonus(11) != 8 || GroupBonus(30) != 8 {
+		t.Fatalf("Incorrect group bonus")
+	}
+}
+
+func TestGridFromRows(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	grid[3+11*GRID_WIDTH] = 0
+	grid[5+10*GRID_WIDTH] = 4
+
+	parsed, err := GridFromRows(grid.Rows())
+	if err != nil || parsed != grid {
+		t.Fatalf("Rows did not round trip")
+	}
+
+	if _, err := GridFromRows([]string{"12x..."}); err != ErrBadGrid {
+		t.Fatalf("Expected a bad grid")
+	}
+}
+
+func TestComponents(t *testing.T) {
+	grid, _ := GridFromRows([]string{
+		"2....1",
+		"1102.1",
+		"110221",
+	})
+	var before Grid = grid
+
+	components := Components(&grid)
+	if grid != before {
+		t.Fatalf("Components changed the grid")
+	}
+
+	if len(components) != 4 {
+		t.Fatalf("Found %d components, expected 4", len(components))
+	}
+
+	var expected = []struct {
+		colour uint8
+		size   int
+		skulls int
+	}{
+		{2, 1, 0},
+		{1, 3, 0}, // the right column does not wrap onto the next row
+		{1, 4, 2},
+		{2, 3, 2},
+	}
+
+	for i, e := range expected {
+		c := components[i]
+		if c.Colour != e.colour || c.Size != e.size || c.Skulls.Count() != e.skulls || c.Members.Count() != e.size {
+			t.Fatalf("Component %d is colour %d size %d skulls %d", i, c.Colour, c.Size, c.Skulls.Count())
+		}
+	}
+
+	if !components[2].Skulls.Intersects(components[3].Skulls) {
+		t.Fatalf("Expected the skull between the groups to touch both")
+	}
+}
+
+// referencePlace is a plain reading of the rules, kept slow and obvious so
+// the engine can be checked against it. It returns the chain count and
+// score of the placement.
+func referencePlace(grid *Grid, position int, rotation int, colours [2]uint8) (int, int) {
+	drop := func(x int, colour uint8) {
+		for y := GRID_HEIGHT - 1; y >= 0; y-- {
+			if grid[x+y*GRID_WIDTH] == EMPTY_SPACE {
+				grid[x+y*GRID_WIDTH] = colour
+				return
+			}
+		}
+	}
+
+	switch rotation {
+	case 0:
+		drop(position, colours[0])
+		drop(position+1, colours[1])
+	case 1:
+		drop(position, colours[0])
+		drop(position, colours[1])
+	case 2:
+		drop(position, colours[0])
+		drop(position-1, colours[1])
+	case 3:
+		drop(position, colours[1])
+		drop(position, colours[0])
+	}
+
+	var chains, score int = 0, 0
+	for {
+		var labels [GRID_WIDTH * GRID_HEIGHT]int
+		var sizes []int = []int{0}
+		var groupColours []uint8 = []uint8{0}
+
+		var walk func(x int, y int, colour uint8, label int)
+		walk = func(x int, y int, colour uint8, label int) {
+			if x < 0 || x >= GRID_WIDTH || y < 0 || y >= GRID_HEIGHT {
+				return
+			}
+			if grid[x+y*GRID_WIDTH] != colour || labels[x+y*GRID_WIDTH] != 0 {
+				return
+			}
+			labels[x+y*GRID_WIDTH] = label
+			sizes[label]++
+			walk(x-1, y, colour, label)
+			walk(x+1, y, colour, label)
+			walk(x, y-1, colour, label)
+			walk(x, y+1, colour, label)
+		}
+
+		for y := 0; y < GRID_HEIGHT; y++ {
+			for x := 0; x < GRID_WIDTH; x++ {
+				colour := grid[x+y*GRID_WIDTH]
+				if colour >= 1 && colour <= 5 && labels[x+y*GRID_WIDTH] == 0 {
+					sizes = append(sizes, 0)
+					groupColours = append(groupColours, colour)
+					walk(x, y, colour, len(sizes)-1)
+				}
+			}
+		}
+
+		var clear [GRID_WIDTH * GRID_HEIGHT]bool
+		var blocks, bonus int = 0, 0
+		var seen [6]bool
+		for label := 1; label < len(sizes); label++ {
+			if sizes[label] < 4 {
+				continue
+			}
+			blocks += sizes[label]
+			seen[groupColours[label]] = true
+			if sizes[label] >= 11 {
+				bonus += 8
+			} else {
+				bonus += sizes[label] - 4
+			}
+		}
+
+		if blocks == 0 {
+			return chains, score
+		}
+
+		for y := 0; y < GRID_HEIGHT; y++ {
+			for x := 0; x < GRID_WIDTH; x++ {
+				label := labels[x+y*GRID_WIDTH]
+				if label == 0 || sizes[label] < 4 {
+					continue
+				}
+				clear[x+y*GRID_WIDTH] = true
+				for _, d := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+					nx, ny := x+d[0], y+d[1]
+					if nx >= 0 && nx < GRID_WIDTH && ny >= 0 && ny < GRID_HEIGHT && grid[nx+ny*GRID_WIDTH] == 0 {
+						clear[nx+ny*GRID_WIDTH] = true
+					}
+				}
+			}
+		}
+
+		for i := range clear {
+			if clear[i] {
+				grid[i] = EMPTY_SPACE
+			}
+		}
+
+		for x := 0; x < GRID_WIDTH; x++ {
+			var column []uint8
+			for y := GRID_HEIGHT - 1; y >= 0; y-- {
+				if grid[x+y*GRID_WIDTH] != EMPTY_SPACE {
+					column = append(column, grid[x+y*GRID_WIDTH])
+				}
+			}
+			for y := GRID_HEIGHT - 1; y >= 0; y-- {
+				grid[x+y*GRID_WIDTH] = EMPTY_SPACE
+				if len(column) > 0 {
+					grid[x+y*GRID_WIDTH] = column[0]
+					column = column[1:]
+				}
+			}
+		}
+
+		chains++
+
+		var power int = 0
+		if chains == 2 {
+			power = 8
+		} else if chains > 2 {
+			power = 8 << uint(chains-2)
+		}
+
+		var colourCount int = 0
+		for _, s := range seen {
+			if s {
+				colourCount++
+			}
+		}
+		var colourBonus int = 0
+		if colourCount > 1 {
+			colourBonus = 1 << uint(colourCount-1)
+		}
+
+		multiplier := power + colourBonus + bonus
+		if multiplier < 1 {
+			multiplier = 1
+		}
+		if multiplier > 999 {
+			multiplier = 999
+		}
+		score += 10 * blocks * multiplier
+	}
+}
+
+// randomGrid stacks random blocks and skulls up to a random height in each
+// column, turning any block that would complete a group of four into a
+// skull so nothing is waiting to clear.
+func randomGrid(rng *rand.Rand) Grid {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+
+	for x := 0; x < GRID_WIDTH; x++ {
+		height := rng.Intn(GRID_HEIGHT - 2)
+		for y := GRID_HEIGHT - 1; y >= GRID_HEIGHT-height; y-- {
+			grid[x+y*GRID_WIDTH] = uint8(rng.Intn(6))
+			for _, component := range Components(&grid) {
+				if component.Size >= 4 && component.Members.Has(x+y*GRID_WIDTH) {
+					grid[x+y*GRID_WIDTH] = 0
+				}
+			}
+		}
+	}
+
+	return grid
+}
+
+// renderGrids lays two grids out side by side.
+func renderGrids(leftTitle string, left *Grid, rightTitle string, right *Grid) string {
+	var lines []string = []string{fmt.Sprintf("%-10s%s", leftTitle, rightTitle)}
+	leftRows, rightRows := left.Rows(), right.Rows()
+	for y := range leftRows {
+		var marker string = " "
+		if leftRows[y] != rightRows[y] {
+			marker = "*"
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s %s", leftRows[y], marker, rightRows[y]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDifferential(t *testing.T) {
+	var games, moves int = 500, 30
+	if testing.Short() {
+		games = 50
+	}
+
+	rng := rand.New(rand.NewSource(7))
+	for game := 0; game < games; game++ {
+		grid := randomGrid(rng)
+		reference := grid
+
+		for move := 0; move < moves; move++ {
+			colours := [2]uint8{uint8(rng.Intn(5) + 1), uint8(rng.Intn(5) + 1)}
+			position, rotation := ChoiceToAction(rng.Intn(len(Choices)))
+
+			if CheckPlacement(&grid, position, rotation) != nil {
+				continue
+			}
+
+			before := grid
+			resolution, err := Place(&grid, position, rotation, colours)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			chains, score := referencePlace(&reference, position, rotation, colours)
+
+			if grid != reference || resolution.ChainCount != chains || resolution.Score != score {
+				t.Fatalf("Game %d move %d diverged placing %v at %d rotation %d\nreference %d chains for %d, engine %d chains for %d\nbefore\n%s\n\n%s",
+					game, move, colours, position, rotation, chains, score, resolution.ChainCount, resolution.Score,
+					strings.Join(before.Rows(), "\n"), renderGrids("reference", &reference, "engine", &grid))
+			}
+		}
+	}
+}
+
+// gridFromBytes fills the grid from the top left, one cell per byte, with
+// 6 standing for an empty cell. Cells past the end of data are empty.
+func gridFromBytes(data []byte) Grid {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+		if i < len(data) && data[i]%7 != 6 {
+			grid[i] = data[i] % 7
+		}
+	}
+	return grid
+}
+
+// settle lets the grid fall and turns any group waiting to clear into
+// skulls, leaving a grid the game could reach.
+func settle(grid *Grid) {
+	grid.ApplyGravity()
+	for _, component := range Components(grid) {
+		if component.Size < 4 {
+			continue
+		}
+		for i := range grid {
+			if component.Members.Has(i) {
+				grid[i] = 0
+			}
+		}
+	}
+}
+
+func floating(grid *Grid) bool {
+	for x := 0; x < GRID_WIDTH; x++ {
+		for y := 1; y < GRID_HEIGHT; y++ {
+			if grid[x+(y-1)*GRID_WIDTH] != EMPTY_SPACE && grid[x+y*GRID_WIDTH] == EMPTY_SPACE {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func countBlocks(grid *Grid) (int, int) {
+	var blocks, skulls int = 0, 0
+	for _, cell := range grid {
+		if cell == 0 {
+			skulls++
+		} else if cell != EMPTY_SPACE {
+			blocks++
+		}
+	}
+	return blocks, skulls
+}
+
+var fuzzGrid []byte = []byte{
+	6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
+	6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
+	1, 6, 6, 2, 6, 2, 1, 1, 6, 0, 6, 2, 1, 1, 6, 0, 2, 2, 3, 4, 6, 0, 2, 5,
+}
+
+func FuzzPlace(f *testing.F) {
+	f.Add(fuzzGrid, 2, 0, uint8(1), uint8(2))
+	f.Add(fuzzGrid, 2, 1, uint8(0), uint8(1))
+	f.Add(fuzzGrid, 0, 2, uint8(3), uint8(3))
+	f.Add([]byte{}, 7, -1, uint8(9), uint8(255))
+
+	f.Fuzz(func(t *testing.T, data []byte, position int, rotation int, a uint8, b uint8) {
+		grid := gridFromBytes(data)
+		settle(&grid)
+		before := grid
+
+		// Anything out of range must be refused, not panic
+		raw := grid
+		Place(&raw, position, rotation, [2]uint8{a, b})
+
+		colours := [2]uint8{a%5 + 1, b%5 + 1}
+		resolution, err := Place(&grid, position, rotation, colours)
+		if err != nil {
+			if grid != before {
+				t.Fatalf("Grid changed by a refused placement")
+			}
+			return
+		}
+
+		blocksBefore, skullsBefore := countBlocks(&before)
+		blocks, skulls := countBlocks(&grid)
+		if blocks != blocksBefore+2-resolution.ClearedBlocks || skulls != skullsBefore-resolution.ClearedSkulls {
+			t.Fatalf("Blocks went from %d to %d and skulls from %d to %d, clearing %d and %d", blocksBefore, blocks, skullsBefore, skulls, resolution.ClearedBlocks, resolution.ClearedSkulls)
+		}
+
+		if floating(&grid) {
+			t.Fatalf("Blocks left floating\n%s", strings.Join(grid.Rows(), "\n"))
+		}
+
+		for _, component := range Components(&grid) {
+			if component.Size >= 4 {
+				t.Fatalf("Group of %d left after resolving\n%s", component.Size, strings.Join(grid.Rows(), "\n"))
+			}
+		}
+
+		// Replay each step: only skulls touching a cleared group may go
+		previous := before
+		leftX, rightX := PairColumns(position, rotation)
+		heights := HighPosition(before)
+		PositionBlockInGridWithY(&previous, leftX, rightX, rotation, colours[0], colours[1], heights[leftX], heights[rightX])
+
+		for _, step := range resolution.Steps {
+			var cleared Mask
+			var stepBlocks int = 0
+			for _, component := range Components(&previous) {
+				if component.Size >= 4 {
+					cleared = cleared.Or(component.Skulls)
+					stepBlocks += component.Size
+				}
+			}
+
+			if step.Skulls != cleared.Count() || step.Blocks() != stepBlocks {
+				t.Fatalf("Step %d cleared %d blocks and %d skulls, expected %d and %d", step.Step, step.Blocks(), step.Skulls, stepBlocks, cleared.Count())
+			}
+
+			previous = step.Grid
+		}
+
+		if previous != grid {
+			t.Fatalf("Last step does not match the grid left")
+		}
+	})
+}
+
+func FuzzApplyGravity(f *testing.F) {
+	f.Add(fuzzGrid)
+	f.Add([]byte{1, 2, 3, 4, 5, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		grid := gridFromBytes(data)
+		before := grid
+
+		grid.ApplyGravity()
+
+		if floating(&grid) {
+			t.Fatalf("Blocks left floating\n%s", strings.Join(grid.Rows(), "\n"))
+		}
+
+		for x := 0; x < GRID_WIDTH; x++ {
+			var was, is []uint8
+			for y := 0; y < GRID_HEIGHT; y++ {
+				if before[x+y*GRID_WIDTH] != EMPTY_SPACE {
+					was = append(was, before[x+y*GRID_WIDTH])
+				}
+				if grid[x+y*GRID_WIDTH] != EMPTY_SPACE {
+					is = append(is, grid[x+y*GRID_WIDTH])
+				}
+			}
+			if string(was) != string(is) {
+				t.Fatalf("Column %d went from %v to %v", x, was, is)
+			}
+		}
+
+		if grid.ApplyGravity() != 0 {
+			t.Fatalf("Blocks still fell after gravity")
+		}
+	})
+}
+
+func FuzzFindConnectedBlocks(f *testing.F) {
+	f.Add(fuzzGrid, 0, 11)
+	f.Add(fuzzGrid, 5, 9)
+	f.Add(fuzzGrid, -1, 12)
+
+	f.Fuzz(func(t *testing.T, data []byte, x int, y int) {
+		grid := gridFromBytes(data)
+		before := grid
+		var visited Grid
+
+		colour, count, skulls := FindConnectedBlocks(&grid, x, y, &visited)
+
+		var removed, removedSkulls int = 0, 0
+		for i := range grid {
+			if grid[i] == before[i] {
+				continue
+			}
+			if grid[i] != EMPTY_SPACE || (before[i] != colour && before[i] != 0) {
+				t.Fatalf("Cell %d went from %d to %d", i, before[i], grid[i])
+			}
+			removed++
+			if before[i] == 0 {
+				removedSkulls++
+			}
+		}
+
+		if count <= 3 && removed != 0 {
+			t.Fatalf("Group of %d removed %d cells", count, removed)
+		}
+
+		if count > 3 && (removed != count+skulls || removedSkulls != skulls) {
+			t.Fatalf("Group of %d with %d skulls removed %d cells", count, skulls, removed)
+		}
+	})
+}
+
+func FuzzPositionBlockInGridWithY(f *testing.F) {
+	f.Add(fuzzGrid, 2, 1, 0, 0)
+	f.Add(fuzzGrid, 0, 2, 3, 3)
+	f.Add(fuzzGrid, 5, 9, -4, 40)
+
+	f.Fuzz(func(t *testing.T, data []byte, position int, rotation int, leftY int, rightY int) {
+		grid := gridFromBytes(data)
+		settle(&grid)
+		before := grid
+
+		leftX, rightX := PairColumns(position, rotation)
+
+		// Any heights at all must be refused, not panic
+		raw := grid
+		if y0, y1 := PositionBlockInGridWithY(&raw, leftX, rightX, rotation, 1, 2, leftY, rightY); y0 == -1 && y1 == -1 && raw != grid {
+			t.Fatalf("Grid changed by a refused placement")
+		}
+
+		if CheckPlacement(&grid, position, rotation) != nil {
+			return
+		}
+
+		heights := HighPosition(grid)
+		PositionBlockInGridWithY(&grid, leftX, rightX, rotation, 1, 2, heights[leftX], heights[rightX])
+
+		var filled int = 0
+		for i := range grid {
+			if grid[i] != before[i] {
+				if before[i] != EMPTY_SPACE {
+					t.Fatalf("Cell %d was overwritten", i)
+				}
+				filled++
+			}
+		}
+
+		if filled != 2 || floating(&grid) {
+			t.Fatalf("Pair filled %d cells\n%s", filled, strings.Join(grid.Rows(), "\n"))
+		}
+	})
+}
+
+// randomPermutation maps each colour to another, keeping skulls as they are.
+func randomPermutation(rng *rand.Rand) [6]uint8 {
+	var permutation [6]uint8
+	for i, colour := range rng.Perm(5) {
+		permutation[i+1] = uint8(colour + 1)
+	}
+	return permutation
+}
+
+func permuteGrid(grid *Grid, permutation [6]uint8) Grid {
+	var permuted Grid = *grid
+	for i, cell := range grid {
+		if cell != EMPTY_SPACE {
+			permuted[i] = permutation[cell]
+		}
+	}
+	return permuted
+}
+
+func mirrorGrid(grid *Grid) Grid {
+	var mirrored Grid
+	for y := 0; y < GRID_HEIGHT; y++ {
+		for x := 0; x < GRID_WIDTH; x++ {
+			mirrored[GRID_WIDTH-1-x+y*GRID_WIDTH] = grid[x+y*GRID_WIDTH]
+		}
+	}
+	return mirrored
+}
+
+// mirrorChoice is the choice that drops the pair the same way on the
+// mirrored grid: the column flips and a pair pointing right points left.
+func mirrorChoice(choice int) int {
+	position, rotation := ChoiceToAction(choice)
+	position = GRID_WIDTH - 1 - position
+	if rotation == 0 || rotation == 2 {
+		rotation = 2 - rotation
+	}
+
+	for i, mirrored := range Choices {
+		if mirrored == [2]int{position, rotation} {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestColourPermutation(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	for game := 0; game < 300; game++ {
+		grid := randomGrid(rng)
+		permutation := randomPermutation(rng)
+		permuted := permuteGrid(&grid, permutation)
+
+		for move := 0; move < 20; move++ {
+			colours := [2]uint8{uint8(rng.Intn(5) + 1), uint8(rng.Intn(5) + 1)}
+			position, rotation := ChoiceToAction(rng.Intn(len(Choices)))
+
+			resolution, err := Place(&grid, position, rotation, colours)
+			permutedResolution, permutedErr := Place(&permuted, position, rotation, [2]uint8{permutation[colours[0]], permutation[colours[1]]})
+			if err != permutedErr {
+				t.Fatalf("Placement refused as %v and %v once permuted", err, permutedErr)
+			}
+
+			expected := permuteGrid(&grid, permutation)
+			if permuted != expected || resolution.ChainCount != permutedResolution.ChainCount || resolution.Score != permutedResolution.Score {
+				t.Fatalf("Game %d move %d: %d chains for %d, %d chains for %d once permuted\n%s",
+					game, move, resolution.ChainCount, resolution.Score, permutedResolution.ChainCount, permutedResolution.Score,
+					renderGrids("expected", &expected, "permuted", &permuted))
+			}
+		}
+	}
+}
+
+func TestMirrorSymmetry(t *testing.T) {
+	rng := rand.New(rand.NewSource(13))
+	for game := 0; game < 300; game++ {
+		grid := randomGrid(rng)
+		mirrored := mirrorGrid(&grid)
+
+		for move := 0; move < 20; move++ {
+			colours := [2]uint8{uint8(rng.Intn(5) + 1), uint8(rng.Intn(5) + 1)}
+			choice := rng.Intn(len(Choices))
+			position, rotation := ChoiceToAction(choice)
+			mirroredPosition, mirroredRotation := ChoiceToAction(mirrorChoice(choice))
+
+			// The spawn column is not central so only moves legal both ways compare
+			if CheckPlacement(&grid, position, rotation) != nil || CheckPlacement(&mirrored, mirroredPosition, mirroredRotation) != nil {
+				continue
+			}
+
+			resolution, _ := Place(&grid, position, rotation, colours)
+			mirroredResolution, _ := Place(&mirrored, mirroredPosition, mirroredRotation, colours)
+
+			expected := mirrorGrid(&grid)
+			if mirrored != expected || resolution.ChainCount != mirroredResolution.ChainCount || resolution.Score != mirroredResolution.Score {
+				t.Fatalf("Game %d move %d: %d chains for %d, %d chains for %d mirrored\n%s",
+					game, move, resolution.ChainCount, resolution.Score, mirroredResolution.ChainCount, mirroredResolution.Score,
+					renderGrids("expected", &expected, "mirrored", &mirrored))
+			}
+		}
+	}
+}
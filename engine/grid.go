@@ -0,0 +1,221 @@
+// Package engine implements the rules of the game: the grid, placing pairs,
+// gravity, clearing chains and scoring.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	GRID_WIDTH  int = 6
+	GRID_HEIGHT int = 12
+	EMPTY_SPACE     = 255
+
+	// Pairs appear above the third column from the left
+	SPAWN_COLUMN int = 2
+)
+
+var ErrBadGrid = errors.New("Grid rows are malformed")
+
+var Choices [22][2]int = [22][2]int{
+	{2, 0}, {2, 1}, {2, 2}, {2, 3},
+	{4, 0}, {4, 1}, {4, 2}, {4, 3},
+	{3, 0}, {3, 1}, {3, 2}, {3, 3},
+	{1, 0}, {1, 1}, {1, 2}, {1, 3},
+	{0, 0}, {0, 1}, {0, 3},
+	{5, 1}, {5, 2}, {5, 3},
+}
+
+var ColourString [6]string = [6]string{
+	"Skull",
+	"Blue",
+	"Green",
+	"Pink",
+	"Red",
+	"Yellow",
+}
+
+type Grid [GRID_WIDTH * GRID_HEIGHT]uint8
+
+func ChoiceToAction(choice int) (int, int) {
+	// 6 positions and 4 possible rotations
+	// except at the edges where there are 3 rotations
+	// 4 * 4 + 2 * 3 = 22
+
+	if choice < 0 || choice >= len(Choices) {
+		return -1, -1
+	}
+
+	return Choices[choice][0], Choices[choice][1]
+}
+
+func PairColumns(position int, rotation int) (int, int) {
+	if rotation == 1 || rotation == 3 {
+		return position, position
+	}
+
+	if rotation == 0 {
+		return position, position + 1
+	}
+
+	return position - 1, position
+}
+
+// ApplyGravity lets every block fall as far as it can and returns how many
+// blocks moved.
+func (grid *Grid) ApplyGravity() int {
+	var dropped int = 0
+
+	// fmt.Fprintf(os.Stderr, "ApplyGravity\n")
+
+	// grid.print()
+
+	// not efficient or helping the cacheline
+	for x := 0; x < GRID_WIDTH; x++ {
+		var lastFilled int = GRID_HEIGHT - 1
+		for y := GRID_HEIGHT - 1; y >= 0; y-- {
+			index := x + y*GRID_WIDTH
+			if grid[index] != EMPTY_SPACE {
+				// check above
+				if lastFilled-y > 0 {
+					// drop
+					// fmt.Fprintf(os.Stderr, "replace %d, %d with %d, %d\n", x, y, x, lastFilled)
+					grid[x+lastFilled*GRID_WIDTH] = grid[index]
+					grid[index] = EMPTY_SPACE
+					dropped++
+				}
+
+				lastFilled--
+			}
+		}
+	}
+
+	// fmt.Fprintf(os.Stderr, "applied\n")
+	// grid.print()
+	// fmt.Fprintf(os.Stderr, "ApplyGravity Done\n")
+
+	return dropped
+}
+
+func HighPosition(grid Grid) [GRID_WIDTH]int {
+	var positions [GRID_WIDTH]int
+	for x := 0; x < GRID_WIDTH; x++ {
+		positions[x] = -1
+
+		for y := GRID_HEIGHT - 1; y >= 0; y-- {
+			index := x + y*GRID_WIDTH
+
+			if grid[index] == EMPTY_SPACE {
+				positions[x] = y
+				break
+			}
+		}
+	}
+	return positions
+}
+
+// PositionBlockInGridWithY writes the pair into the grid with its blocks at
+// the given heights and returns the heights of the left and right blocks.
+// Nothing is written, and -1 returned for both, if either block would fall
+// outside the grid.
+func PositionBlockInGridWithY(grid *Grid, leftX int, rightX int, rotation int, colourA uint8, colourB uint8, leftY int, rightY int) (int, int) {
+	var ax, ay, bx, by int
+
+	switch rotation {
+	case 0:
+		ax, ay, bx, by = leftX, leftY, rightX, rightY
+	case 2:
+		ax, ay, bx, by = rightX, rightY, leftX, leftY
+	case 1:
+		ax, ay, bx, by = rightX, leftY, leftX, leftY-1
+	case 3:
+		ax, ay, bx, by = leftX, leftY-1, rightX, leftY
+	default:
+		return -1, -1
+	}
+
+	inside := func(x int, y int) bool {
+		return x >= 0 && x < GRID_WIDTH && y >= 0 && y < GRID_HEIGHT
+	}
+
+	if !inside(ax, ay) || !inside(bx, by) {
+		return -1, -1
+	}
+
+	grid[ax+ay*GRID_WIDTH] = colourA
+	grid[bx+by*GRID_WIDTH] = colourB
+
+	switch rotation {
+	case 1:
+		return leftY, leftY - 1
+	case 3:
+		return leftY - 1, leftY
+	}
+
+	return leftY, rightY
+}
+
+// Rows writes the grid out the way the referee sends it, one string per row
+// from the top.
+func (grid *Grid) Rows() []string {
+	var rows []string
+	for y := 0; y < GRID_HEIGHT; y++ {
+		var row []byte = make([]byte, GRID_WIDTH)
+		for x := 0; x < GRID_WIDTH; x++ {
+			if grid[x+y*GRID_WIDTH] == EMPTY_SPACE {
+				row[x] = '.'
+			} else {
+				row[x] = grid[x+y*GRID_WIDTH] + '0'
+			}
+		}
+		rows = append(rows, string(row))
+	}
+
+	return rows
+}
+
+// GridFromRows reads a grid written the way Rows writes it. Fewer than
+// GRID_HEIGHT rows fill the bottom of the grid, leaving the top empty.
+func GridFromRows(rows []string) (Grid, error) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+
+	if len(rows) > GRID_HEIGHT {
+		return grid, ErrBadGrid
+	}
+
+	var top int = GRID_HEIGHT - len(rows)
+	for y, row := range rows {
+		if len(row) != GRID_WIDTH {
+			return grid, ErrBadGrid
+		}
+
+		for x := 0; x < GRID_WIDTH; x++ {
+			switch {
+			case row[x] == '.':
+			case row[x] >= '0' && row[x] <= '5':
+				grid[x+(top+y)*GRID_WIDTH] = row[x] - '0'
+			default:
+				return grid, ErrBadGrid
+			}
+		}
+	}
+
+	return grid, nil
+}
+
+func (grid *Grid) Print(title string) {
+	fmt.Fprintf(os.Stderr, "%s\n{\n", title)
+	for y := 0; y < GRID_HEIGHT; y++ {
+		fmt.Fprintf(os.Stderr, "    ")
+		for x := 0; x < GRID_WIDTH; x++ {
+			fmt.Fprintf(os.Stderr, "%02d, ", grid[x+y*GRID_WIDTH])
+		}
+		fmt.Fprintf(os.Stderr, "\n")
+	}
+	fmt.Fprintf(os.Stderr, "}\n")
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"errors"
+)
+
+var ErrNoMoreSpace = errors.New("No more space!")
+
+var ErrInvalidChoice = errors.New("Position or rotation out of range")
+
+var ErrInvalidColour = errors.New("Pair colour out of range")
+
+var ErrSpawnBlocked = errors.New("Spawn column is full")
+
+var ErrPathBlocked = errors.New("Path to the column is blocked")
+
+// CheckPlacement returns nil when the pair can be dropped with the given
+// position and rotation, otherwise the reason it cannot. A pair appears at
+// the top of SPAWN_COLUMN and slides along the top row to its target, so the
+// spawn cell and every column it crosses must still be open.
+func CheckPlacement(grid *Grid, position int, rotation int) error {
+	if rotation < 0 || rotation > 3 || position < 0 || position >= GRID_WIDTH {
+		return ErrInvalidChoice
+	}
+
+	leftX, rightX := PairColumns(position, rotation)
+	if leftX < 0 || rightX >= GRID_WIDTH {
+		return ErrInvalidChoice
+	}
+
+	if grid[SPAWN_COLUMN] != EMPTY_SPACE {
+		return ErrSpawnBlocked
+	}
+
+	for x := SPAWN_COLUMN - 1; x > rightX; x-- {
+		if grid[x] != EMPTY_SPACE {
+			return ErrPathBlocked
+		}
+	}
+
+	for x := SPAWN_COLUMN + 1; x < leftX; x++ {
+		if grid[x] != EMPTY_SPACE {
+			return ErrPathBlocked
+		}
+	}
+
+	// Gravity keeps columns packed so only the top cells need checking
+	if rotation == 1 || rotation == 3 {
+		if grid[leftX+GRID_WIDTH] != EMPTY_SPACE {
+			return ErrNoMoreSpace
+		}
+	} else if grid[leftX] != EMPTY_SPACE || grid[rightX] != EMPTY_SPACE {
+		return ErrNoMoreSpace
+	}
+
+	return nil
+}
+
+// GenerateMoves lists the choices that can legally place the pair, dropping
+// any that leave exactly the same grid as an earlier choice. That happens
+// when both blocks share a colour, e.g. rotations 1 and 3 in one column.
+func GenerateMoves(grid *Grid, colours [2]uint8) []int {
+	var highestPositions [GRID_WIDTH]int = HighPosition(*grid)
+	var placed [22]Grid
+	var moves []int = make([]int, 0, 22)
+
+	for choice := 0; choice < 22; choice++ {
+		position, rotation := ChoiceToAction(choice)
+		if CheckPlacement(grid, position, rotation) != nil {
+			continue
+		}
+
+		leftX, rightX := PairColumns(position, rotation)
+
+		var result Grid = *grid
+		PositionBlockInGridWithY(&result, leftX, rightX, rotation, colours[0], colours[1], highestPositions[leftX], highestPositions[rightX])
+
+		var duplicate bool = false
+		for _, move := range moves {
+			if placed[move] == result {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			placed[choice] = result
+			moves = append(moves, choice)
+		}
+	}
+
+	return moves
+}
+
+// IsLost reports whether the next pair has nowhere to appear.
+func IsLost(grid *Grid) bool {
+	return grid[SPAWN_COLUMN] != EMPTY_SPACE
+}
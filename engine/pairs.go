@@ -0,0 +1,17 @@
+package engine
+
+import (
+	"math/rand"
+)
+
+// Only this many pairs are given, plies past them play sampled pairs
+const KNOWN_PAIRS int = 8
+
+// SamplePairs fills in pairs the way the game generates them, each block an
+// independent uniform pick from the five colours.
+func SamplePairs(rng *rand.Rand, pairs *[8][2]uint8) {
+	for i := range pairs {
+		pairs[i][0] = uint8(rng.Intn(5) + 1)
+		pairs[i][1] = uint8(rng.Intn(5) + 1)
+	}
+}
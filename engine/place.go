@@ -0,0 +1,20 @@
+package engine
+
+// Place drops the pair into the grid at position and rotation and resolves
+// the chains it sets off.
+func Place(grid *Grid, position int, rotation int, colours [2]uint8) (Resolution, error) {
+	if colours[0] < 1 || colours[0] > 5 || colours[1] < 1 || colours[1] > 5 {
+		return Resolution{}, ErrInvalidColour
+	}
+
+	if err := CheckPlacement(grid, position, rotation); err != nil {
+		return Resolution{}, err
+	}
+
+	leftX, rightX := PairColumns(position, rotation)
+	highestPositions := HighPosition(*grid)
+
+	leftY, rightY := PositionBlockInGridWithY(grid, leftX, rightX, rotation, colours[0], colours[1], highestPositions[leftX], highestPositions[rightX])
+
+	return ResolveChains(grid, leftX, leftY, rightX, rightY), nil
+}
@@ -0,0 +1,3 @@
+module github.com/edwardadd/smash_the_code
+
+go 1.21
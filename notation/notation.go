@@ -0,0 +1,174 @@
+// Package notation writes a position of the game as a single line of text
+// and reads it back, so positions can be pasted into bug reports, test
+// tables and the analysis command.
+//
+// A position is six fields separated by spaces:
+//
+//	<player grid> <opponent grid> <pairs> <turn> <player score> <opponent score>
+//
+// Grids list their rows from the top, separated by '/'. Each block is a
+// letter, s for a skull then b, g, p, r and y for blue, green, pink, red and
+// yellow, and a digit counts empty cells. The pairs are the eight upcoming
+// pairs as two letters each, separated by ','. An empty grid, for example,
+// is 6/6/6/6/6/6/6/6/6/6/6/6.
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+var ErrBadNotation = errors.New("Position notation is malformed")
+
+// Letters for skulls and each colour, indexed by block
+const LETTERS string = "sbgpry"
+
+// Position is everything the notation records about a turn.
+type Position struct {
+	Player   engine.Grid
+	Opponent engine.Grid
+	Pairs    [engine.KNOWN_PAIRS][2]uint8
+	Turn     int
+
+	PlayerScore   int
+	OpponentScore int
+}
+
+// FormatGrid writes a grid in the notation. Cells holding no known block are
+// written as '?', which does not parse, rather than passed off as empty.
+func FormatGrid(grid *engine.Grid) string {
+	var rows []string
+	for y := 0; y < engine.GRID_HEIGHT; y++ {
+		var row strings.Builder
+		var empty int = 0
+		for x := 0; x < engine.GRID_WIDTH; x++ {
+			cell := grid[x+y*engine.GRID_WIDTH]
+			if cell == engine.EMPTY_SPACE {
+				empty++
+				continue
+			}
+
+			if empty > 0 {
+				row.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			row.WriteByte(letter(cell))
+		}
+
+		if empty > 0 {
+			row.WriteString(strconv.Itoa(empty))
+		}
+		rows = append(rows, row.String())
+	}
+
+	return strings.Join(rows, "/")
+}
+
+// ParseGrid reads a grid written in the notation.
+func ParseGrid(text string) (engine.Grid, error) {
+	var grid engine.Grid
+	for i := range grid {
+		grid[i] = engine.EMPTY_SPACE
+	}
+
+	rows := strings.Split(text, "/")
+	if len(rows) != engine.GRID_HEIGHT {
+		return grid, fmt.Errorf("%w: %d rows in %q", ErrBadNotation, len(rows), text)
+	}
+
+	for y, row := range rows {
+		var x int = 0
+		for _, c := range row {
+			if c >= '1' && c <= '9' {
+				x += int(c - '0')
+				continue
+			}
+
+			block := strings.IndexRune(LETTERS, c)
+			if block < 0 || x >= engine.GRID_WIDTH {
+				return grid, fmt.Errorf("%w: row %q", ErrBadNotation, row)
+			}
+
+			grid[x+y*engine.GRID_WIDTH] = uint8(block)
+			x++
+		}
+
+		if x != engine.GRID_WIDTH {
+			return grid, fmt.Errorf("%w: row %q is not %d cells", ErrBadNotation, row, engine.GRID_WIDTH)
+		}
+	}
+
+	return grid, nil
+}
+
+// Format writes the position in the notation.
+func Format(position *Position) string {
+	var pairs []string
+	for _, pair := range position.Pairs {
+		pairs = append(pairs, string([]byte{letter(pair[0]), letter(pair[1])}))
+	}
+
+	return fmt.Sprintf("%s %s %s %d %d %d",
+		FormatGrid(&position.Player), FormatGrid(&position.Opponent), strings.Join(pairs, ","),
+		position.Turn, position.PlayerScore, position.OpponentScore)
+}
+
+func letter(colour uint8) byte {
+	if int(colour) >= len(LETTERS) {
+		return '?'
+	}
+	return LETTERS[colour]
+}
+
+// Parse reads a position written in the notation.
+func Parse(text string) (Position, error) {
+	var position Position
+
+	fields := strings.Fields(text)
+	if len(fields) != 6 {
+		return position, fmt.Errorf("%w: %d fields, expected 6", ErrBadNotation, len(fields))
+	}
+
+	var err error
+	if position.Player, err = ParseGrid(fields[0]); err != nil {
+		return position, err
+	}
+	if position.Opponent, err = ParseGrid(fields[1]); err != nil {
+		return position, err
+	}
+
+	pairs := strings.Split(fields[2], ",")
+	if len(pairs) != engine.KNOWN_PAIRS {
+		return position, fmt.Errorf("%w: %d pairs, expected %d", ErrBadNotation, len(pairs), engine.KNOWN_PAIRS)
+	}
+
+	for i, pair := range pairs {
+		if len(pair) != 2 {
+			return position, fmt.Errorf("%w: pair %q", ErrBadNotation, pair)
+		}
+
+		for j := 0; j < 2; j++ {
+			// Pairs are never skulls
+			colour := strings.IndexByte(LETTERS, pair[j])
+			if colour < 1 {
+				return position, fmt.Errorf("%w: pair %q", ErrBadNotation, pair)
+			}
+			position.Pairs[i][j] = uint8(colour)
+		}
+	}
+
+	numbers := []*int{&position.Turn, &position.PlayerScore, &position.OpponentScore}
+	for i, number := range numbers {
+		value, err := strconv.Atoi(fields[3+i])
+		if err != nil || value < 0 {
+			return position, fmt.Errorf("%w: %q is not a count", ErrBadNotation, fields[3+i])
+		}
+		*number = value
+	}
+
+	return position, nil
+}
@@ -0,0 +1,75 @@
+package notation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+func TestRoundTrip(t *testing.T) {
+	player, _ := engine.GridFromRows([]string{"...1..", "2..1.0", "345100"})
+	opponent, _ := engine.GridFromRows(nil)
+
+	position := Position{
+		Player:        player,
+		Opponent:      opponent,
+		Pairs:         [engine.KNOWN_PAIRS][2]uint8{{1, 2}, {3, 4}, {5, 5}, {1, 1}, {2, 3}, {4, 5}, {1, 3}, {2, 4}},
+		Turn:          12,
+		PlayerScore:   840,
+		OpponentScore: 70,
+	}
+
+	var text string = "6/6/6/6/6/6/6/6/6/3b2/g2b1s/prybss 6/6/6/6/6/6/6/6/6/6/6/6 bg,pr,yy,bb,gp,ry,bp,gr 12 840 70"
+
+	if formatted := Format(&position); formatted != text {
+		t.Fatalf("Formatted as %q", formatted)
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if parsed != position {
+		t.Fatalf("Position did not round trip")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var empty string = "6/6/6/6/6/6/6/6/6/6/6/6"
+	var pairs string = "bg,pr,yy,bb,gp,ry,bp,gr"
+
+	var cases = []string{
+		"",
+		empty + " " + empty + " " + pairs + " 0 0",
+		"6/6 " + empty + " " + pairs + " 0 0 0",
+		"6/6/6/6/6/6/6/6/6/6/6/5 " + empty + " " + pairs + " 0 0 0",
+		"6/6/6/6/6/6/6/6/6/6/6/6b " + empty + " " + pairs + " 0 0 0",
+		"6/6/6/6/6/6/6/6/6/6/6/bbxbbb " + empty + " " + pairs + " 0 0 0",
+		empty + " " + empty + " bg,pr " + " 0 0 0",
+		empty + " " + empty + " bs,pr,yy,bb,gp,ry,bp,gr 0 0 0",
+		empty + " " + empty + " " + pairs + " 0 -5 0",
+	}
+
+	for _, c := range cases {
+		if _, err := Parse(c); !errors.Is(err, ErrBadNotation) {
+			t.Fatalf("Expected %q to be refused, got %v", c, err)
+		}
+	}
+}
+
+func TestFormatMarksUnknownCells(t *testing.T) {
+	grid, _ := engine.GridFromRows([]string{"1....."})
+	grid[engine.GRID_WIDTH*engine.GRID_HEIGHT-1] = 9
+
+	formatted := FormatGrid(&grid)
+	if !strings.HasSuffix(formatted, "/b4?") {
+		t.Fatalf("Expected the unknown cell as '?', got %q", formatted)
+	}
+
+	if _, err := ParseGrid(formatted); !errors.Is(err, ErrBadNotation) {
+		t.Fatalf("An unknown cell should not read back, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Package protocol reads the game state from the referee and writes moves
+// back.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+var ErrBadRow = errors.New("Grid row is too short")
+
+func ParseNextBlocks(r io.Reader, nextColours *[engine.KNOWN_PAIRS][2]uint8) error {
+	for i := 0; i < engine.KNOWN_PAIRS; i++ {
+		// colorA: color of the first block
+		// colorB: color of the attached block
+		var colorA, colorB uint8
+		if _, err := fmt.Fscan(r, &colorA, &colorB); err != nil {
+			return err
+		}
+		nextColours[i][0] = colorA
+		nextColours[i][1] = colorB
+	}
+
+	return nil
+}
+
+func ParseGrid(r io.Reader, grid *engine.Grid) error {
+	for i := 0; i < engine.GRID_HEIGHT; i++ {
+		var row string
+		if _, err := fmt.Fscan(r, &row); err != nil {
+			return err
+		}
+
+		if len(row) < engine.GRID_WIDTH {
+			return ErrBadRow
+		}
+
+		for j := 0; j < engine.GRID_WIDTH; j++ {
+			if row[j] == '.' {
+				grid[i*engine.GRID_WIDTH+j] = engine.EMPTY_SPACE
+			} else {
+
+				value := uint8(row[j])
+				grid[i*engine.GRID_WIDTH+j] = value - 48
+			}
+		}
+	}
+
+	return nil
+}
+
+func Output(w io.Writer, position int, rotation int, message string) {
+	if message == "" {
+		fmt.Fprintf(w, "%d %d\n", position, rotation)
+	} else {
+		fmt.Fprintf(w, "%d %d %s\n", position, rotation, message)
+	}
+}
@@ -0,0 +1,49 @@
+package protocol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+func TestParseTurn(t *testing.T) {
+	input := "1 2\n3 4\n5 1\n2 3\n4 5\n1 1\n2 2\n3 3\n" +
+		strings.Repeat("......\n", 10) + "0.....\n12..45\n"
+
+	reader := strings.NewReader(input)
+
+	var nextColours [engine.KNOWN_PAIRS][2]uint8
+	if err := ParseNextBlocks(reader, &nextColours); err != nil {
+		t.Fatalf("Parse next blocks failed - %v", err)
+	}
+
+	if nextColours[0] != [2]uint8{1, 2} || nextColours[7] != [2]uint8{3, 3} {
+		t.Fatalf("Wrong pairs - %v", nextColours)
+	}
+
+	var grid engine.Grid
+	if err := ParseGrid(reader, &grid); err != nil {
+		t.Fatalf("Parse grid failed - %v", err)
+	}
+
+	if grid[0] != engine.EMPTY_SPACE || grid[60] != 0 || grid[66] != 1 || grid[68] != engine.EMPTY_SPACE || grid[71] != 5 {
+		t.Fatalf("Wrong grid - %v", grid)
+	}
+
+	if err := ParseGrid(reader, &grid); err == nil {
+		t.Fatalf("Expected an error at the end of the input")
+	}
+}
+
+func TestOutput(t *testing.T) {
+	var buffer bytes.Buffer
+
+	Output(&buffer, 3, 1, "")
+	Output(&buffer, 0, 2, "Hello")
+
+	if buffer.String() != "3 1\n0 2 Hello\n" {
+		t.Fatalf("Wrong output - %q", buffer.String())
+	}
+}
@@ -0,0 +1,130 @@
+// Package referee plays a match between two players by the game's rules, so
+// the bot can be played against itself or checked offline.
+package referee
+
+import (
+	"math/rand"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+// Points scored for each skull sent to the opponent
+const NUISANCE_POINTS int = 70
+
+// Move is where a player drops the pair at the front of the queue.
+type Move struct {
+	Position int
+	Rotation int
+}
+
+// Player is one side of the match.
+type Player struct {
+	Grid  engine.Grid
+	Score int
+
+	// Points towards skulls not yet dropped on the opponent
+	Nuisance int
+
+	// Set once the player can no longer play, with the illegal move's reason
+	Lost bool
+	Err  error
+}
+
+// Match deals both players the same pairs and applies their moves.
+type Match struct {
+	Players [2]Player
+	Turn    int
+
+	queue [][2]uint8
+	rng   *rand.Rand
+}
+
+// NewMatch starts a match on empty grids, drawing pairs from rng.
+func NewMatch(rng *rand.Rand) *Match {
+	match := &Match{rng: rng}
+	for p := range match.Players {
+		for i := range match.Players[p].Grid {
+			match.Players[p].Grid[i] = engine.EMPTY_SPACE
+		}
+	}
+	return match
+}
+
+// Next returns the pairs both players see this turn, the one to place first.
+func (match *Match) Next() [engine.KNOWN_PAIRS][2]uint8 {
+	for len(match.queue) < engine.KNOWN_PAIRS {
+		var pairs [engine.KNOWN_PAIRS][2]uint8
+		engine.SamplePairs(match.rng, &pairs)
+		match.queue = append(match.queue, pairs[:]...)
+	}
+
+	var next [engine.KNOWN_PAIRS][2]uint8
+	copy(next[:], match.queue)
+	return next
+}
+
+// Over reports whether either player has lost.
+func (match *Match) Over() bool {
+	return match.Players[0].Lost || match.Players[1].Lost
+}
+
+// Play applies both players' moves for the turn, then drops the skulls each
+// has earned on the other. A move the rules do not allow loses the game for
+// the player who made it.
+func (match *Match) Play(moves [2]Move) {
+	if match.Over() {
+		return
+	}
+
+	colours := match.Next()[0]
+	match.queue = match.queue[1:]
+	match.Turn++
+
+	for p := range match.Players {
+		player := &match.Players[p]
+
+		resolution, err := engine.Place(&player.Grid, moves[p].Position, moves[p].Rotation, colours)
+		if err != nil {
+			player.Lost = true
+			player.Err = err
+			continue
+		}
+
+		player.Score += resolution.Score
+		player.Nuisance += resolution.Score
+	}
+
+	for p := range match.Players {
+		player := &match.Players[p]
+		opponent := &match.Players[1-p]
+
+		// Skulls only fall in whole rows
+		rows := player.Nuisance / (NUISANCE_POINTS * engine.GRID_WIDTH)
+		player.Nuisance -= rows * NUISANCE_POINTS * engine.GRID_WIDTH
+		dropSkulls(&opponent.Grid, rows)
+	}
+
+	for p := range match.Players {
+		player := &match.Players[p]
+		if !player.Lost && engine.IsLost(&player.Grid) {
+			player.Lost = true
+		}
+	}
+}
+
+// dropSkulls adds rows of skulls on top of every column, as far as there is
+// room.
+func dropSkulls(grid *engine.Grid, rows int) {
+	highestPositions := engine.HighPosition(*grid)
+
+	for row := 0; row < rows; row++ {
+		for x := 0; x < engine.GRID_WIDTH; x++ {
+			if highestPositions[x] < 0 {
+				continue
+			}
+
+			grid[x+highestPositions[x]*engine.GRID_WIDTH] = 0
+			highestPositions[x]--
+		}
+	}
+}
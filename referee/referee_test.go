@@ -0,0 +1,87 @@
+package referee
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+func TestIllegalMoveLoses(t *testing.T) {
+	match := NewMatch(rand.New(rand.NewSource(1)))
+
+	var rows []string
+	for y := 0; y < engine.GRID_HEIGHT; y++ {
+		rows = append(rows, []string{".1....", ".2...."}[y%2])
+	}
+	blocked, err := engine.GridFromRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	match.Players[0].Grid = blocked
+
+	// The pair cannot slide past the full column to reach the left edge
+	match.Play([2]Move{{0, 1}, {0, 1}})
+
+	if !match.Players[0].Lost || match.Players[0].Err != engine.ErrPathBlocked {
+		t.Fatalf("Expected a loss with %v, got %v %v", engine.ErrPathBlocked, match.Players[0].Lost, match.Players[0].Err)
+	}
+	if match.Players[1].Lost {
+		t.Fatalf("Legal move lost with %v", match.Players[1].Err)
+	}
+	if !match.Over() {
+		t.Fatal("Match should be over")
+	}
+}
+
+func TestSkullsDropInRows(t *testing.T) {
+	match := NewMatch(rand.New(rand.NewSource(1)))
+	match.queue = [][2]uint8{{1, 1}}
+
+	grid, err := engine.GridFromRows([]string{"111..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	match.Players[0].Grid = grid
+	match.Players[0].Nuisance = 400
+
+	match.Play([2]Move{{3, 0}, {5, 1}})
+
+	// Five blocks score 50, enough with what was owed for one row
+	if match.Players[0].Score != 50 || match.Players[0].Nuisance != 30 {
+		t.Fatalf("Expected score 50 and 30 owed, got %d and %d", match.Players[0].Score, match.Players[0].Nuisance)
+	}
+
+	var skulls int = 0
+	for _, cell := range match.Players[1].Grid {
+		if cell == 0 {
+			skulls++
+		}
+	}
+	if skulls != engine.GRID_WIDTH {
+		t.Fatalf("Expected a row of skulls, got %d", skulls)
+	}
+}
+
+func TestRandomMatchEnds(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	match := NewMatch(rng)
+
+	for match.Turn < 1000 && !match.Over() {
+		var moves [2]Move
+		colours := match.Next()[0]
+		for p := range moves {
+			legal := engine.GenerateMoves(&match.Players[p].Grid, colours)
+			if len(legal) == 0 {
+				continue
+			}
+			position, rotation := engine.ChoiceToAction(legal[rng.Intn(len(legal))])
+			moves[p] = Move{position, rotation}
+		}
+		match.Play(moves)
+	}
+
+	if !match.Over() {
+		t.Fatalf("Random players still going after %d turns", match.Turn)
+	}
+}
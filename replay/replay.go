@@ -0,0 +1,62 @@
+// Package replay records each turn of a game, what the bot saw, what it
+// played and how the search went, as one JSON object per line.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/edwardadd/smash_the_code/engine"
+	"github.com/edwardadd/smash_the_code/search"
+)
+
+// Turn is one line of a replay file. Grids are kept in the referee's row
+// format so the files can be read by eye.
+type Turn struct {
+	Turn        int                          `json:"turn"`
+	NextColours [engine.KNOWN_PAIRS][2]uint8 `json:"next"`
+	PlayerGrid  []string                     `json:"player"`
+	CpuGrid     []string                     `json:"cpu"`
+	Move        search.Move                  `json:"move"`
+	Stats       search.SearchStats           `json:"stats"`
+}
+
+func NewTurn(turn int, nextColours *[engine.KNOWN_PAIRS][2]uint8, playerGrid *engine.Grid, cpuGrid *engine.Grid, move search.Move, stats search.SearchStats) *Turn {
+	return &Turn{
+		Turn:        turn,
+		NextColours: *nextColours,
+		PlayerGrid:  playerGrid.Rows(),
+		CpuGrid:     cpuGrid.Rows(),
+		Move:        move,
+		Stats:       stats,
+	}
+}
+
+type Writer struct {
+	encoder *json.Encoder
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{encoder: json.NewEncoder(w)}
+}
+
+func (writer *Writer) Write(turn *Turn) error {
+	return writer.encoder.Encode(turn)
+}
+
+// Read returns every turn recorded in r.
+func Read(r io.Reader) ([]Turn, error) {
+	var turns []Turn
+
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for decoder.More() {
+		var turn Turn
+		if err := decoder.Decode(&turn); err != nil {
+			return turns, err
+		}
+		turns = append(turns, turn)
+	}
+
+	return turns, nil
+}
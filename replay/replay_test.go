@@ -0,0 +1,55 @@
+package replay
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/edwardadd/smash_the_code/engine"
+	"github.com/edwardadd/smash_the_code/search"
+)
+
+func TestWriteRead(t *testing.T) {
+	var grid engine.Grid
+	for i := range grid {
+		grid[i] = engine.EMPTY_SPACE
+	}
+	grid[engine.GRID_WIDTH*engine.GRID_HEIGHT-1] = 4
+	grid[engine.GRID_WIDTH*engine.GRID_HEIGHT-2] = 0
+
+	var nextColours [engine.KNOWN_PAIRS][2]uint8 = [engine.KNOWN_PAIRS][2]uint8{
+		{5, 3}, {3, 2}, {2, 1}, {1, 4}, {1, 2}, {5, 1}, {1, 2}, {3, 3},
+	}
+
+	stats := search.SearchStats{
+		Turn:     1,
+		Rollouts: 10,
+		Search:   time.Millisecond,
+		Children: []search.ChildStats{{Position: 2, Rotation: 1, Visits: 10, Score: 5}},
+	}
+
+	var buffer bytes.Buffer
+	writer := NewWriter(&buffer)
+	first := NewTurn(0, &nextColours, &grid, &grid, search.Move{Position: 2, Rotation: 1}, stats)
+	second := NewTurn(1, &nextColours, &grid, &grid, search.Move{Position: 0, Rotation: 0, Message: "Hi"}, stats)
+	writer.Write(first)
+	writer.Write(second)
+
+	if bytes.Count(buffer.Bytes(), []byte("\n")) != 2 {
+		t.Fatalf("Expected one line per turn\n%s", buffer.String())
+	}
+
+	turns, err := Read(&buffer)
+	if err != nil {
+		t.Fatalf("Read failed - %v", err)
+	}
+
+	if len(turns) != 2 || !reflect.DeepEqual(turns[0], *first) || !reflect.DeepEqual(turns[1], *second) {
+		t.Fatalf("Turns did not survive the round trip\n%+v", turns)
+	}
+
+	if turns[0].PlayerGrid[engine.GRID_HEIGHT-1] != "....04" {
+		t.Fatalf("Wrong bottom row %q", turns[0].PlayerGrid[engine.GRID_HEIGHT-1])
+	}
+}
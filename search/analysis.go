@@ -0,0 +1,94 @@
+package search
+
+import "github.com/edwardadd/smash_the_code/engine"
+
+// ChoiceAnalysis is how the last search rated one of the legal choices at
+// the root.
+type ChoiceAnalysis struct {
+	Choice   int `json:"choice"`
+	Position int `json:"position"`
+	Rotation int `json:"rotation"`
+
+	// Set when the choice leaves the same grid as the earlier choice SameAs,
+	// which the search explored in its place and whose ratings are copied
+	Duplicate bool `json:"duplicate,omitempty"`
+	SameAs    int  `json:"same_as,omitempty"`
+
+	// Zero visits when the search never tried the choice
+	Visits int `json:"visits"`
+
+	// Average score of the rollouts through the choice and the best
+	Mean int  `json:"mean"`
+	Max  int  `json:"max"`
+	Lost bool `json:"lost,omitempty"`
+
+	// Longest chain along the choice's principal variation
+	Chain     int        `json:"chain"`
+	Principal []PlyStats `json:"principal"`
+}
+
+// Analyse lists every legal choice of the last turn searched, in the order
+// of engine.Choices.
+func (game *Game) Analyse() []ChoiceAnalysis {
+	var analysis []ChoiceAnalysis
+	if game.searched == nil {
+		return analysis
+	}
+
+	root := game.searched
+	colours := game.nextColours[0]
+	var highestPositions [engine.GRID_WIDTH]int = engine.HighPosition(root.grid)
+
+	// The search only explores the first of the choices leaving each grid
+	var placed [22]engine.Grid
+
+	for choice := range engine.Choices {
+		position, rotation := engine.ChoiceToAction(choice)
+		if engine.CheckPlacement(&root.grid, position, rotation) != nil {
+			continue
+		}
+
+		leftX, rightX := engine.PairColumns(position, rotation)
+		placed[choice] = root.grid
+		engine.PositionBlockInGridWithY(&placed[choice], leftX, rightX, rotation, colours[0], colours[1], highestPositions[leftX], highestPositions[rightX])
+
+		var duplicate bool = false
+		for i, earlier := range analysis {
+			if earlier.Duplicate || placed[earlier.Choice] != placed[choice] {
+				continue
+			}
+
+			entry := analysis[i]
+			entry.Choice, entry.Position, entry.Rotation = choice, position, rotation
+			entry.Duplicate = true
+			entry.SameAs = earlier.Choice
+			analysis = append(analysis, entry)
+			duplicate = true
+			break
+		}
+		if duplicate {
+			continue
+		}
+
+		entry := ChoiceAnalysis{Choice: choice, Position: position, Rotation: rotation}
+
+		node := root.nodes[choice]
+		if node != nil && node.err == nil && !node.invalid && !node.stale {
+			entry.Visits = node.visits
+			entry.Mean = node.mean()
+			entry.Max = node.score
+			entry.Lost = node.lost
+			entry.Principal, _ = principalVariation(node)
+
+			for _, ply := range entry.Principal {
+				if ply.ChainCount > entry.Chain {
+					entry.Chain = ply.ChainCount
+				}
+			}
+		}
+
+		analysis = append(analysis, entry)
+	}
+
+	return analysis
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"github.com/edwardadd/smash_the_code/timing"
+)
+
+// Weights scale the terms of the heuristic evaluation.
+type Weights struct {
+	// Chains: firing at the right time, blocks and skulls
+	// cleared, and how soon a chain fires after skulls land
+	ChainTiming int `json:"chain_timing"`
+	ChainBlock  int `json:"chain_block"`
+	ChainSkull  int `json:"chain_skull"`
+	ChainSoon   int `json:"chain_soon"`
+
+	// Building: a flat bonus for not losing anything, a low grid and
+	// blocks stacked next to their own colour
+	Base       int `json:"base"`
+	Height     int `json:"height"`
+	Neighbours int `json:"neighbours"`
+
+	// Progress towards the chain template the grid fits best, times the
+	// chain it fires
+	Template int `json:"template"`
+
+	// Survival: turns lived, room left in the spawn column and free cells
+	SurvivalTurn     int `json:"survival_turn"`
+	SurvivalHeadroom int `json:"survival_headroom"`
+	SurvivalFree     int `json:"survival_free"`
+}
+
+// Config holds everything a game's search can be tuned with.
+type Config struct {
+	Samples          int   `json:"samples"`
+	Depth            int   `json:"depth"`
+	Determinizations int   `json:"determinizations"`
+	Seed             int64 `json:"seed"`
+
+	// Milliseconds from the start of the turn to stop sampling at, no
+	// limit but the samples if zero
+	Budget int `json:"budget"`
+
+	// When to fire chains rather than keep building
+	Timing timing.Policy `json:"timing"`
+
+	DangerHeadroom  int `json:"danger_headroom"`
+	DangerFreeCells int `json:"danger_free_cells"`
+
+	Weights Weights `json:"weights"`
+
+	// Plies of the planned line shown as the move's message, none if zero
+	PlanPlies int `json:"plan_plies"`
+
+	// Play the opening book's placements while the game follows it
+	Book bool `json:"book"`
+}
+
+// DefaultConfig is the configuration the bot is submitted with, kept as code
+// so the submission needs no files.
+func DefaultConfig() Config {
+	return Config{
+		Samples:          SAMPLES,
+		Depth:            DEPTH,
+		Determinizations: DETERMINIZATIONS,
+		Seed:             643,
+		Budget:           BUDGET,
+		Timing:           timing.DefaultPolicy(),
+		DangerHeadroom:   DANGER_HEADROOM,
+		DangerFreeCells:  DANGER_FREE_CELLS,
+		Book:             true,
+		Weights: Weights{
+			ChainTiming:      10,
+			ChainBlock:       100,
+			ChainSkull:       200,
+			ChainSoon:        1000,
+			Base:             100,
+			Height:           60,
+			Neighbours:       10,
+			Template:         1,
+			SurvivalTurn:     1000,
+			SurvivalHeadroom: 100,
+			SurvivalFree:     10,
+		},
+	}
+}
@@ -0,0 +1,31 @@
+package search
+
+import (
+	"math/rand"
+)
+
+// searchContext holds the state a game's search reads besides the tree, so
+// that several games can be searched side by side in one process.
+type searchContext struct {
+	config Config
+
+	// Chain length worth firing this turn
+	target int
+
+	// Staying alive matters more than building chains
+	survival bool
+
+	// Turn being searched, which survival counts the turns alive from
+	turn int
+
+	stats SearchStats
+	rng   *rand.Rand
+}
+
+func newSearchContext(config Config) *searchContext {
+	return &searchContext{
+		config: config,
+		target: config.Timing.TargetChain,
+		rng:    rand.New(rand.NewSource(config.Seed)),
+	}
+}
@@ -0,0 +1,162 @@
+package search
+
+import (
+	"github.com/edwardadd/smash_the_code/engine"
+	"github.com/edwardadd/smash_the_code/templates"
+	"github.com/edwardadd/smash_the_code/timing"
+)
+
+// inDanger reports whether the grid is close enough to topping out that
+// staying alive matters more than building chains.
+func inDanger(config *Config, grid *engine.Grid) bool {
+	free := freeCells(grid)
+
+	highestPositions := engine.HighPosition(*grid)
+
+	return highestPositions[engine.SPAWN_COLUMN]+1 <= config.DangerHeadroom || free <= config.DangerFreeCells
+}
+
+func countSkulls(grid *engine.Grid) int {
+	var skulls int = 0
+	for i := 0; i < engine.GRID_WIDTH*engine.GRID_HEIGHT; i++ {
+		if grid[i] == 0 {
+			skulls++
+		}
+	}
+	return skulls
+}
+
+func freeCells(grid *engine.Grid) int {
+	var free int = 0
+	for i := 0; i < engine.GRID_WIDTH*engine.GRID_HEIGHT; i++ {
+		if grid[i] == engine.EMPTY_SPACE {
+			free++
+		}
+	}
+	return free
+}
+
+// bestChain is the longest chain any placement of the pair sets off.
+func bestChain(grid *engine.Grid, colours [2]uint8) int {
+	var best int = 0
+	for _, choice := range engine.GenerateMoves(grid, colours) {
+		position, rotation := engine.ChoiceToAction(choice)
+
+		var result engine.Grid = *grid
+		resolution, err := engine.Place(&result, position, rotation, colours)
+		if err == nil && resolution.ChainCount > best {
+			best = resolution.ChainCount
+		}
+	}
+	return best
+}
+
+// situation describes the turn for the fire timing policy. Both players are
+// dealt the same pairs, so the opponent's threat is what the next one sets
+// off on their grid.
+func situation(config *Config, playerGrid *engine.Grid, cpuGrid *engine.Grid, incomingSkulls int, next [2]uint8) timing.Situation {
+	playerHeights := engine.HighPosition(*playerGrid)
+	cpuHeights := engine.HighPosition(*cpuGrid)
+
+	if incomingSkulls < 0 {
+		incomingSkulls = 0
+	}
+
+	return timing.Situation{
+		Headroom:          playerHeights[engine.SPAWN_COLUMN] + 1,
+		InDanger:          inDanger(config, playerGrid),
+		IncomingSkulls:    incomingSkulls,
+		OpponentHeadroom:  cpuHeights[engine.SPAWN_COLUMN] + 1,
+		OpponentFreeCells: freeCells(cpuGrid),
+		OpponentChain:     bestChain(cpuGrid, next),
+	}
+}
+
+// survivalScore rewards lines that stay alive for longer and leave the most
+// room, above all in the spawn column.
+func survivalScore(weights *Weights, grid *engine.Grid, turnsAlive int) int {
+	free := freeCells(grid)
+
+	highestPositions := engine.HighPosition(*grid)
+
+	return turnsAlive*weights.SurvivalTurn + (highestPositions[engine.SPAWN_COLUMN]+1)*weights.SurvivalHeadroom + free*weights.SurvivalFree
+}
+
+// evaluate scores the grid left once the chains set off by a placement have
+// resolved, rewarding chains of the expected length and otherwise a tidy,
+// low grid with plenty of same coloured neighbours, shaped like a known
+// chain.
+func evaluate(context *searchContext, grid *engine.Grid, highestPositions *[engine.GRID_WIDTH]int, resolution engine.Resolution, next int, invalid bool) int {
+	var weights *Weights = &context.config.Weights
+	var chainCount int = resolution.ChainCount
+	var groupCount int = resolution.Groups
+	var averageNeighbouringBlockCount int = resolution.GroupedBlocks
+	var averageChainBlock int = resolution.ClearedBlocks
+
+	// Higher the score the lower the average line
+	var heightBonus int = 0
+	for i := 0; i < engine.GRID_WIDTH; i++ {
+		heightBonus += highestPositions[i]
+	}
+	heightBonus = int(float32(heightBonus) / 6.0)
+
+	// average Groupd size
+	if groupCount > 0 {
+		averageNeighbouringBlockCount = averageNeighbouringBlockCount / groupCount
+
+		averageChainBlock = averageChainBlock / groupCount
+	} else {
+		averageNeighbouringBlockCount = 0
+
+		averageChainBlock = 0
+	}
+	var averageStacked int = 0
+	var prevColour uint8 = engine.EMPTY_SPACE
+	var prevColourCount int = 1
+	var colourGroups int = 1
+	for x := 0; x < engine.GRID_WIDTH; x++ {
+		prevColourCount = 1
+		prevColour = grid[x+0*engine.GRID_WIDTH]
+		for y := 1; y < engine.GRID_HEIGHT; y++ {
+			if grid[x+y*engine.GRID_WIDTH] == prevColour {
+				prevColourCount++
+			} else {
+				averageStacked += prevColourCount
+				colourGroups++
+				prevColour = grid[x+y*engine.GRID_WIDTH]
+				prevColourCount = 1
+			}
+		}
+	}
+
+	averageStacked = averageStacked / colourGroups
+
+	var totalColours = 0
+	for i := 0; i < engine.GRID_WIDTH*engine.GRID_HEIGHT; i++ {
+		col := grid[i]
+		if col != engine.EMPTY_SPACE && col != 0 {
+			totalColours++
+		}
+	}
+
+	var groupColoursUp int = int(float64(totalColours) / float64(groupCount))
+
+	var chainSoonAs int = 0
+	if invalid {
+		chainSoonAs = chainCount * (8 - next) * weights.ChainSoon
+	}
+	chainScore := timing.Score(chainCount, context.target)
+	actualScore := chainCount * (chainScore*weights.ChainTiming + averageChainBlock*chainCount*weights.ChainBlock + resolution.ClearedSkulls*weights.ChainSkull*chainCount)
+
+	if chainCount > 0 {
+		return chainSoonAs + actualScore
+	}
+
+	var template int = 0
+	if weights.Template != 0 {
+		fit := templates.Best(grid)
+		template = fit.Value() * weights.Template
+	}
+
+	return resolution.GroupsOfThree*groupColoursUp + averageStacked + weights.Base + heightBonus*weights.Height + (averageStacked*averageNeighbouringBlockCount-3)*weights.Neighbours + template
+}
@@ -0,0 +1,254 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+func betterChoice(context *searchContext, node *Node, currentTurn int, nextBlocks *[8][2]uint8) int {
+	// choice := float32(rand.Intn(22)) / 22
+
+	// rng := (1 - choice * choice) * 21
+
+	// fmt.Fprintf(os.Stderr, "%f %f\n", rng, choice)
+	// return int(rng)
+	moves := node.legalMoves(currentTurn, nextBlocks)
+	if len(moves) == 0 {
+		return -1
+	}
+
+	return moves[context.rng.Intn(len(moves))]
+}
+
+func explore(context *searchContext, choice int, node *Node, currentTurn int, maxDepth int, nextBlocks *[8][2]uint8, exploreType int) error {
+	// fmt.Fprintf(os.Stderr, "Explore Turn %d - %d\n", currentTurn, node.turn)
+	var newNode *Node = nil
+
+	if choice < 0 {
+		return engine.ErrNoMoreSpace
+	}
+
+	if node.nodes[choice] != nil {
+		// fmt.Fprintf(os.Stderr, "Already explored Turn %d - %d, score %d\n", currentTurn, node.turn, node.nodes[choice].score)
+		newNode = node.nodes[choice]
+
+		if newNode.invalid || newNode.stale {
+			var previous engine.Grid = newNode.grid
+
+			newNode.grid = node.grid
+			newNode.moves = nil
+			newNode.lost = false
+			newNode.score = 0
+			newNode.sampledScore = 0
+			newNode.sampledCount = 0
+			newNode.stale = false
+			newNode.message = ""
+			if newNode.invalid {
+				newNode.message = "Damn those skulls!"
+			}
+			newNode.turnExplored = currentTurn
+
+			err := simulate(context, newNode, currentTurn, nextBlocks)
+			newNode.err = err
+
+			if err != nil {
+				return err
+			}
+
+			// Only a changed grid affects what was explored below
+			if newNode.grid != previous {
+				newNode.invalidateChildren()
+			}
+		}
+	} else {
+
+		position, rotation := engine.ChoiceToAction(choice)
+
+		newNode = &Node{
+			choice:       choice,
+			grid:         node.grid,
+			turn:         node.turn + 1,
+			turnExplored: currentTurn,
+			position:     position,
+			rotation:     rotation,
+			score:        0,
+			parent:       node,
+			err:          nil,
+		}
+
+		node.nodes[choice] = newNode
+		context.stats.Nodes++
+
+		err := simulate(context, newNode, currentTurn, nextBlocks)
+		newNode.err = err
+
+		if err != nil {
+			return err
+		}
+	}
+
+	newNode.visits++
+
+	if newNode.lost {
+		newNode.recordRollout()
+		return nil
+	}
+
+	depth := newNode.turn - currentTurn
+	context.stats.reachedDepth(depth)
+	if depth >= len(nextBlocks) {
+		if depth < maxDepth {
+			newNode.determinize(context, maxDepth-depth, currentTurn)
+		}
+		newNode.recordRollout()
+		return nil
+	}
+
+	switch exploreType {
+
+	case 0:
+		if newNode.turn-currentTurn < maxDepth {
+			choice := betterChoice(context, newNode, currentTurn, nextBlocks)
+			if choice < 0 {
+				// Nowhere left to put the next pair
+				newNode.markLost(currentTurn)
+				newNode.recordRollout()
+				return nil
+			}
+
+			err := explore(context, choice, newNode, currentTurn, maxDepth, nextBlocks, exploreType)
+			return err
+		}
+	case 1:
+		if newNode.turn-currentTurn < maxDepth {
+			moves := newNode.legalMoves(currentTurn, nextBlocks)
+			if len(moves) == 0 {
+				newNode.markLost(currentTurn)
+				newNode.recordRollout()
+			}
+
+			for _, i := range moves {
+				explore(context, i, newNode, currentTurn, maxDepth, nextBlocks, exploreType)
+			}
+			return nil
+		}
+	}
+
+	newNode.recordRollout()
+	return nil
+}
+
+func simulate(context *searchContext, node *Node, currentTurn int, nextBlocks *[8][2]uint8) error {
+	var leftX, rightX int
+
+	// Fill the grid
+	next := (node.turn - currentTurn) - 1
+	if next < 0 || next >= len(nextBlocks) {
+		return ErrBeyondHorizon
+	}
+	// fmt.Fprintf(os.Stderr, "SImulate Turn %d - %d - %d %d\n", currentTurn, node.turn, node.position, node.rotation)
+
+	colour := nextBlocks[next]
+
+	if err := engine.CheckPlacement(&node.grid, node.position, node.rotation); err != nil {
+		return err
+	}
+
+	leftX, rightX = engine.PairColumns(node.position, node.rotation)
+
+	var highestPositions [engine.GRID_WIDTH]int = engine.HighPosition(node.grid)
+
+	leftY, rightY := engine.PositionBlockInGridWithY(&node.grid, leftX, rightX, node.rotation, colour[0], colour[1], highestPositions[leftX], highestPositions[rightX])
+
+	// Check for clearable blocks
+	// If found then update grid and check again
+	var tempGrid engine.Grid = node.grid
+	resolution := engine.ResolveChains(&tempGrid, leftX, leftY, rightX, rightY)
+
+	// Survival only counts staying alive, so the evaluation would be wasted
+	var finalScore int
+	if context.survival {
+		finalScore = survivalScore(&context.config.Weights, &tempGrid, node.turn-context.turn)
+	} else {
+		finalScore = evaluate(context, &tempGrid, &highestPositions, resolution, next, node.invalid)
+	}
+
+	// Update node
+	node.score = finalScore
+	node.evaluation = finalScore
+	node.chainCount = resolution.ChainCount
+	node.grid = tempGrid
+	node.invalid = false
+	if node.chainCount > 0 {
+		node.message = fmt.Sprintf("Go! Go! Gadget Chain x%d", node.chainCount)
+	}
+
+	if engine.IsLost(&node.grid) {
+		node.markLost(currentTurn)
+		return nil
+	}
+
+	// Back propagate the score
+	node.backPropagateScore()
+
+	return nil
+}
+
+// determinize plays the given number of plies past the known pairs on
+// several sampled futures and scores the node with their average, which may
+// be worse than its own grid when the futures go badly.
+func (node *Node) determinize(context *searchContext, plies int, currentTurn int) {
+	for i := 0; i < context.config.Determinizations; i++ {
+		var pairs [8][2]uint8
+		engine.SamplePairs(context.rng, &pairs)
+
+		node.sampledScore += node.rollout(context, &pairs, plies, currentTurn)
+		node.sampledCount++
+		context.stats.Sampled++
+	}
+
+	node.score = node.sampledScore / node.sampledCount
+
+	// A lower score is not undone by backpropagation, so rescore the path
+	for parent := node.parent; parent != nil; parent = parent.parent {
+		parent.rescore()
+	}
+}
+
+// rollout plays random moves for the sampled pairs on throwaway nodes, so
+// futures that are only guesses never enter the tree, and returns the score
+// of the grid the line ends on.
+func (node *Node) rollout(context *searchContext, pairs *[8][2]uint8, plies int, currentTurn int) int {
+	var line *Node = &Node{turn: node.turn, grid: node.grid, score: node.evaluation}
+
+	for ply := 0; ply < plies && ply < len(pairs); ply++ {
+		choice := betterChoice(context, line, node.turn, pairs)
+		if choice < 0 {
+			return LOST_SCORE + line.turn - currentTurn
+		}
+
+		position, rotation := engine.ChoiceToAction(choice)
+		var next *Node = &Node{
+			choice:   choice,
+			grid:     line.grid,
+			turn:     line.turn + 1,
+			position: position,
+			rotation: rotation,
+		}
+
+		// The sampled pairs are numbered from the horizon node
+		if simulate(context, next, node.turn, pairs) != nil {
+			break
+		}
+		context.stats.reachedDepth(next.turn - currentTurn)
+
+		if next.lost {
+			return LOST_SCORE + next.turn - currentTurn
+		}
+
+		line = next
+	}
+
+	return line.score
+}
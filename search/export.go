@@ -0,0 +1,151 @@
+package search
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ExportOptions prune the tree before it is exported. Zero leaves that
+// dimension unlimited.
+type ExportOptions struct {
+	// Plies below the root to include
+	Depth int
+
+	// Only the best scoring children of each node are kept
+	TopK int
+}
+
+// ExportedNode is a node of the search tree as written out by the exporters.
+type ExportedNode struct {
+	Choice     int             `json:"choice"`
+	Position   int             `json:"position"`
+	Rotation   int             `json:"rotation"`
+	Turn       int             `json:"turn"`
+	Score      int             `json:"score"`
+	ChainCount int             `json:"chain_count"`
+	Visits     int             `json:"visits"`
+	Invalid    bool            `json:"invalid,omitempty"`
+	Stale      bool            `json:"stale,omitempty"`
+	Lost       bool            `json:"lost,omitempty"`
+	Err        string          `json:"err,omitempty"`
+	Children   []*ExportedNode `json:"children,omitempty"`
+}
+
+// ExportTree returns the tree searched during the last turn played, pruned
+// with options.
+func (game *Game) ExportTree(options ExportOptions) *ExportedNode {
+	if game.searched == nil {
+		return nil
+	}
+
+	return exportNode(game.searched, options, 0)
+}
+
+func exportNode(node *Node, options ExportOptions, depth int) *ExportedNode {
+	exported := &ExportedNode{
+		Choice:     node.choice,
+		Position:   node.position,
+		Rotation:   node.rotation,
+		Turn:       node.turn,
+		Score:      node.score,
+		ChainCount: node.chainCount,
+		Visits:     node.visits,
+		Invalid:    node.invalid,
+		Stale:      node.stale,
+		Lost:       node.lost,
+	}
+
+	if node.err != nil {
+		exported.Err = node.err.Error()
+	}
+
+	if options.Depth > 0 && depth >= options.Depth {
+		return exported
+	}
+
+	var children []*Node
+	for _, child := range node.nodes {
+		if child != nil {
+			children = append(children, child)
+		}
+	}
+
+	// Best first, keeping the order of the choices among equal scores
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].score > children[j].score
+	})
+
+	if options.TopK > 0 && len(children) > options.TopK {
+		children = children[:options.TopK]
+	}
+
+	for _, child := range children {
+		exported.Children = append(exported.Children, exportNode(child, options, depth+1))
+	}
+
+	return exported
+}
+
+func WriteJSON(w io.Writer, root *ExportedNode) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(root)
+}
+
+// WriteDOT writes the tree as a Graphviz graph. Lost lines are red, nodes
+// waiting to be re-simulated grey, dotted if only their score is out of date,
+// and placements that failed dashed.
+func WriteDOT(w io.Writer, root *ExportedNode) error {
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintln(writer, "digraph search {")
+	fmt.Fprintln(writer, "\tnode [shape=box, fontname=\"monospace\"];")
+
+	var id int = 0
+	var write func(node *ExportedNode, parent int)
+	write = func(node *ExportedNode, parent int) {
+		var self int = id
+		id++
+
+		var label string
+		if parent < 0 {
+			label = fmt.Sprintf("root\\nscore %d\\nvisits %d", node.Score, node.Visits)
+		} else {
+			label = fmt.Sprintf("%d: %d,%d\\nscore %d\\nchains %d\\nvisits %d", node.Choice, node.Position, node.Rotation, node.Score, node.ChainCount, node.Visits)
+		}
+		if node.Err != "" {
+			label += "\\n" + node.Err
+		}
+
+		var style string
+		switch {
+		case node.Err != "":
+			style = ", style=dashed"
+		case node.Lost:
+			style = ", color=red"
+		case node.Invalid:
+			style = ", color=grey"
+		case node.Stale:
+			style = ", color=grey, style=dotted"
+		}
+
+		fmt.Fprintf(writer, "\tn%d [label=\"%s\"%s];\n", self, label, style)
+		if parent >= 0 {
+			fmt.Fprintf(writer, "\tn%d -> n%d;\n", parent, self)
+		}
+
+		for _, child := range node.Children {
+			write(child, self)
+		}
+	}
+
+	if root != nil {
+		write(root, -1)
+	}
+
+	fmt.Fprintln(writer, "}")
+	return writer.Flush()
+}
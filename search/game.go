@@ -0,0 +1,409 @@
+// Package search decides where to drop each pair by sampling a tree of
+// future placements.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/edwardadd/smash_the_code/book"
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+const (
+	SAMPLES int = 2000
+	DEPTH   int = 10
+
+	// Sampled futures averaged each time a line reaches the known horizon
+	DETERMINIZATIONS int = 2
+
+	// Milliseconds into the turn after which sampling stops, CodinGame
+	// allows 100
+	BUDGET int = 85
+
+	// Lines that lose score below anything that survives
+	LOST_SCORE int = -1000000
+
+	// Survival mode kicks in with this little room left
+	DANGER_HEADROOM   int = 4
+	DANGER_FREE_CELLS int = 18
+)
+
+// How the grid observed at the start of a turn compares with the one the
+// tree predicted for it
+const (
+	GRID_MATCHES = iota
+	GRID_SKULLS_ADDED
+	GRID_DIVERGED
+)
+
+var ErrBeyondHorizon = errors.New("Pair is beyond the known queue")
+
+var ErrAlreadyExplored = errors.New("Leaf already explored")
+
+type Stats struct {
+	nextHistogram         [5]uint8
+	playerGridHistogram   [5]uint8
+	maximumPossibleChains [5]uint8
+}
+
+type Game struct {
+	nextColours [engine.KNOWN_PAIRS][2]uint8
+	playerGrid  engine.Grid
+	cpuGrid     engine.Grid
+	turn        int
+	stats       Stats
+	node        *Node
+
+	// Root of the last turn's search, kept for the exporters
+	searched *Node
+
+	context *searchContext
+
+	// The opening book's line and the grid it expects this turn
+	opening     []book.Move
+	openingGrid engine.Grid
+}
+
+// Move is the placement chosen for a turn.
+type Move struct {
+	Position int    `json:"position"`
+	Rotation int    `json:"rotation"`
+	Message  string `json:"message,omitempty"`
+}
+
+// NewGame starts a game searched with the given configuration, drawing from
+// its own random source.
+func NewGame(config Config) *Game {
+	game := &Game{context: newSearchContext(config)}
+	game.initialise()
+	return game
+}
+
+// NewGameAt starts a game joined at the given turn, as when analysing a
+// position from the middle of a game.
+func NewGameAt(config Config, turn int) *Game {
+	game := NewGame(config)
+	game.turn = turn
+	game.node.turn = turn
+	return game
+}
+
+func (game *Game) initialise() {
+	game.turn = 0
+	game.node = &Node{
+		turn:   game.turn,
+		parent: nil,
+	}
+}
+
+// Play takes the state read at the start of a turn and returns the move to
+// make.
+func (game *Game) Play(nextColours *[engine.KNOWN_PAIRS][2]uint8, playerGrid *engine.Grid, cpuGrid *engine.Grid) Move {
+	return game.PlayFrom(time.Now(), nextColours, playerGrid, cpuGrid)
+}
+
+// PlayFrom is Play for a turn that started at start, which the time budget
+// is counted from.
+func (game *Game) PlayFrom(start time.Time, nextColours *[engine.KNOWN_PAIRS][2]uint8, playerGrid *engine.Grid, cpuGrid *engine.Grid) Move {
+	defer func() { game.turn++ }()
+
+	game.context.stats = SearchStats{Turn: game.turn}
+	game.context.turn = game.turn
+
+	game.nextColours = *nextColours
+	game.playerGrid = *playerGrid
+	game.cpuGrid = *cpuGrid
+
+	// Nothing was predicted before the first turn played
+	if game.searched == nil {
+		game.node.grid = game.playerGrid
+	}
+
+	//game.node.grid.Print("Previous Grid")
+
+	// game.analyseNextColours()
+	change := compareGrids(&game.node.grid, &game.playerGrid)
+	incomingSkulls := countSkulls(&game.playerGrid) - countSkulls(&game.node.grid)
+
+	switch change {
+	case GRID_SKULLS_ADDED:
+		// Keep the tree, children are re-simulated as the search reaches them
+		game.node.grid = game.playerGrid
+		game.node.moves = nil
+		game.node.invalidateChildren()
+	case GRID_DIVERGED:
+		// The prediction was wrong, nothing below it can be trusted
+		fmt.Fprintln(os.Stderr, "Prediction diverged, discarding tree")
+		game.node = &Node{
+			grid: game.playerGrid,
+		}
+	}
+
+	// Keep turns relative to now, whatever path led to this root
+	game.node.rebase(game.turn, game.turn+engine.KNOWN_PAIRS)
+
+	//game.playerGrid.Print("Current Grid")
+
+	previousTarget, previousSurvival := game.context.target, game.context.survival
+
+	game.context.survival = inDanger(&game.context.config, &game.playerGrid)
+	if game.context.survival {
+		fmt.Fprintln(os.Stderr, "Survival mode")
+	}
+
+	game.context.target = game.context.config.Timing.Target(situation(&game.context.config, &game.playerGrid, &game.cpuGrid, incomingSkulls, game.nextColours[0]))
+	game.context.stats.Target = game.context.target
+
+	// Carried scores were worked out for last turn's target, and survival
+	// scores count the turns alive from then
+	if game.context.survival || game.context.survival != previousSurvival || game.context.target != previousTarget {
+		game.node.markChildrenStale()
+	}
+
+	searchStart := time.Now()
+	var deadline time.Time = start.Add(time.Duration(game.context.config.Budget) * time.Millisecond)
+
+	// A move from the book needs no search
+	var bestNode *Node = game.openingNode()
+	var samples int = 0
+	if bestNode == nil {
+		samples = game.context.config.Samples
+	}
+
+	for i := 0; i < samples; i++ {
+		if game.context.config.Budget > 0 && time.Now().After(deadline) {
+			samples = i
+			break
+		}
+
+		game.node.visits++
+		explore(game.context, betterChoice(game.context, game.node, game.turn, &game.nextColours), game.node, game.turn, game.context.config.Depth, &game.nextColours, 0)
+	}
+
+	game.recordStats(samples, time.Since(searchStart))
+	game.searched = game.node
+
+	if bestNode == nil {
+		//find choice with greatest score
+		var nodeCount int
+		bestNode, nodeCount = game.chooseBestNode()
+
+		if bestNode == nil {
+			// No more good moves... so game over!
+			return Move{0, 0, "It's game over, man! IT'S GAME OVER!"}
+		}
+
+		if bestNode.score == 0 && nodeCount > 1 {
+			bestNode = game.luckOfTheDraw()
+			bestNode.message = "Luck of the draw"
+		}
+	}
+
+	principal, grid := principalVariation(bestNode)
+	game.context.stats.Principal = principal
+	game.context.stats.PrincipalGrid = grid.Rows()
+
+	game.node = bestNode
+	bestNode.parent = nil
+
+	var message string = bestNode.message
+	if game.context.config.PlanPlies > 0 {
+		message = game.context.stats.Plan(game.context.config.PlanPlies)
+	}
+
+	return Move{bestNode.position, bestNode.rotation, message}
+}
+
+// openingNode simulates the child the opening book plays this turn, nil once
+// the book has nothing for the game or skulls have knocked it off its line.
+func (game *Game) openingNode() *Node {
+	if !game.context.config.Book {
+		return nil
+	}
+
+	if game.turn == 0 {
+		var empty engine.Grid
+		for i := range empty {
+			empty[i] = engine.EMPTY_SPACE
+		}
+
+		game.opening = nil
+		if game.playerGrid == empty {
+			game.opening = book.Lookup(&game.nextColours)
+			game.openingGrid = empty
+		}
+	}
+
+	if game.turn >= len(game.opening) || game.playerGrid != game.openingGrid {
+		game.opening = nil
+		return nil
+	}
+
+	move := game.opening[game.turn]
+	for choice, action := range engine.Choices {
+		if action != [2]int{move.Position, move.Rotation} {
+			continue
+		}
+
+		game.node.visits++
+		explore(game.context, choice, game.node, game.turn, 1, &game.nextColours, 0)
+
+		node := game.node.nodes[choice]
+		if node == nil || node.err != nil || node.lost {
+			break
+		}
+
+		engine.Place(&game.openingGrid, move.Position, move.Rotation, game.nextColours[0])
+		node.message = "By the book"
+		return node
+	}
+
+	game.opening = nil
+	return nil
+}
+
+// Stats returns what the search did during the last turn played.
+func (game *Game) Stats() SearchStats {
+	return game.context.stats
+}
+
+func (game *Game) recordStats(samples int, elapsed time.Duration) {
+	stats := &game.context.stats
+	stats.Rollouts = samples
+	stats.Search = elapsed
+
+	for _, node := range game.node.nodes {
+		if node == nil || node.err != nil || node.invalid || node.stale {
+			continue
+		}
+
+		stats.Children = append(stats.Children, ChildStats{
+			Position: node.position,
+			Rotation: node.rotation,
+			Visits:   node.visits,
+			Score:    node.score,
+			Lost:     node.lost,
+		})
+	}
+}
+
+func (game *Game) chooseBestNode() (*Node, int) {
+	var bestNode *Node = nil
+	var lostNode *Node = nil
+	var nodeCount int
+
+	for n := 0; n < 22; n++ {
+		var node *Node = game.node.nodes[n]
+
+		if node == nil || node.err != nil || node.invalid || node.stale {
+			continue
+		}
+
+		if node.lost {
+			// Only played when every line loses, holding out as long as possible
+			if lostNode == nil || node.score > lostNode.score {
+				lostNode = node
+			}
+			continue
+		}
+
+		nodeCount++
+
+		if bestNode == nil {
+			bestNode = node
+		}
+
+		if node.score > bestNode.score {
+			bestNode = node
+		}
+	}
+
+	if bestNode == nil && lostNode != nil {
+		lostNode.message = "Going down fighting"
+		return lostNode, 1
+	}
+
+	return bestNode, nodeCount
+}
+
+// luckOfTheDraw picks uniformly among the children still worth playing, for
+// when the search found nothing to tell them apart.
+func (game *Game) luckOfTheDraw() *Node {
+	var candidates []*Node
+	for _, node := range game.node.nodes {
+		if node == nil || node.err != nil || node.invalid || node.stale || node.lost {
+			continue
+		}
+		candidates = append(candidates, node)
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	return candidates[game.context.rng.Intn(len(candidates))]
+}
+
+func (game *Game) analyseNextColours() {
+	// fmt.Fprintln(os.Stderr, "analyseNextColours")
+	// fmt.Fprintln(os.Stderr, "NC", game.nextColours)
+	// fmt.Fprintln(os.Stderr, "PGrid", game.playerGrid)
+
+	// histogram of colours
+	var histogram [5]uint8
+
+	for _, colours := range game.nextColours {
+		for _, colour := range colours {
+			histogram[colour-1]++
+		}
+	}
+
+	game.stats.nextHistogram = histogram
+
+	histogram = [5]uint8{0, 0, 0, 0, 0}
+	for _, colour := range game.playerGrid {
+		if colour > 0 {
+			histogram[colour-1]++
+		}
+	}
+
+	game.stats.playerGridHistogram = histogram
+
+	for i, colourCount := range game.stats.nextHistogram {
+		histogram[i] += colourCount
+	}
+
+	for i, colourCount := range histogram {
+		game.stats.maximumPossibleChains[i] = colourCount / 4 // rough...
+
+		// fmt.Fprintln(os.Stderr, "Possible Chains ", game.stats.maximumPossibleChains)
+	}
+
+}
+
+// compareGrids classifies the observed grid against the predicted one. Any
+// difference other than skulls landing on top of the predicted blocks means
+// the prediction cannot be reused.
+func compareGrids(predicted *engine.Grid, observed *engine.Grid) int {
+	//predicted.Print("Last predicted:")
+	//observed.Print("Current state:")
+
+	var change int = GRID_MATCHES
+
+	for i := 0; i < engine.GRID_WIDTH*engine.GRID_HEIGHT; i++ {
+		if predicted[i] == observed[i] {
+			continue
+		}
+
+		if predicted[i] != engine.EMPTY_SPACE || observed[i] != 0 {
+			return GRID_DIVERGED
+		}
+
+		change = GRID_SKULLS_ADDED
+	}
+
+	return change
+}
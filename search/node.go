@@ -0,0 +1,224 @@
+package search
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+type Node struct {
+	choice       int
+	grid         engine.Grid
+	score        int
+	chainCount   int
+	turn         int
+	turnExplored int
+	position     int
+	rotation     int
+
+	nodes   [22]*Node
+	parent  *Node
+	err     error
+	message string
+	invalid bool
+	lost    bool
+
+	// Scored under an earlier turn's fire target or survival mode
+	stale bool
+
+	// Score of this node's own grid, before children are taken into account
+	evaluation int
+
+	// Running total of the futures sampled past the known pairs
+	sampledScore int
+	sampledCount int
+
+	// Distinct legal choices for the next pair, nil until generated
+	moves []int
+
+	// Times the search has passed through this node
+	visits int
+
+	// Rollouts that ended at or below this node and the sum of their scores
+	rollouts     int
+	rolloutTotal int
+}
+
+// legalMoves returns the distinct choices for the pair that follows this
+// node, generating them on first use.
+func (node *Node) legalMoves(currentTurn int, nextBlocks *[8][2]uint8) []int {
+	if node.moves == nil {
+		next := node.turn - currentTurn
+		if next >= len(nextBlocks) {
+			return nil
+		}
+
+		node.moves = engine.GenerateMoves(&node.grid, nextBlocks[next])
+	}
+
+	return node.moves
+}
+
+func (node *Node) backPropagateScore() {
+	var parent *Node = node.parent
+	for {
+		if parent == nil {
+			break
+		}
+
+		if parent.score < node.score {
+			parent.score = node.score
+		}
+		parent = parent.parent
+	}
+}
+
+// rebase renumbers the subtree so that this node is at the given turn, the
+// pairs being known up to the horizon turn, and reports whether any score
+// in it had to be dropped.
+func (node *Node) rebase(turn int, horizon int) bool {
+	node.turn = turn
+
+	// Visits are reported per turn
+	node.visits = 0
+	node.rollouts = 0
+	node.rolloutTotal = 0
+
+	// Scored over sampled futures when its pair was unknown, which it no
+	// longer is
+	var changed bool = false
+	if node.sampledCount > 0 && turn < horizon {
+		node.stale = true
+		node.sampledScore = 0
+		node.sampledCount = 0
+		changed = true
+	}
+
+	var below bool = false
+	for _, child := range node.nodes {
+		if child != nil && child.rebase(turn+1, horizon) {
+			below = true
+		}
+	}
+
+	// The dropped scores are still in the maximum carried up
+	if below {
+		node.rescore()
+	}
+
+	return changed || below
+}
+
+// recordRollout adds the score a rollout ended on to this node and every
+// node above it.
+func (node *Node) recordRollout() {
+	var score int = node.score
+	for n := node; n != nil; n = n.parent {
+		n.rollouts++
+		n.rolloutTotal += score
+	}
+}
+
+// mean is the average score of the rollouts through this node.
+func (node *Node) mean() int {
+	if node.rollouts == 0 {
+		return 0
+	}
+	return node.rolloutTotal / node.rollouts
+}
+
+// markChildrenStale flags every node below this one to be re-simulated when
+// the search next reaches it.
+func (node *Node) markChildrenStale() {
+	for _, child := range node.nodes {
+		if child != nil {
+			child.stale = true
+			child.markChildrenStale()
+		}
+	}
+}
+
+func (node *Node) invalidateChildren() {
+	for _, child := range node.nodes {
+		if child != nil {
+			child.invalid = true
+		}
+	}
+}
+
+// markLost scores a node that cannot survive, preferring lines that lose
+// later, and rescores its ancestors so the loss is not hidden behind scores
+// backpropagated before it was found.
+func (node *Node) markLost(currentTurn int) {
+	node.lost = true
+	node.score = LOST_SCORE + node.turn - currentTurn
+	node.evaluation = node.score
+
+	for parent := node.parent; parent != nil; parent = parent.parent {
+		parent.rescore()
+	}
+}
+
+// rescore recomputes the score from the node's evaluation and its children.
+// A node whose every distinct move has been found to lose is lost too.
+func (node *Node) rescore() {
+	if len(node.moves) > 0 {
+		var allLost bool = true
+		var latest int = LOST_SCORE
+
+		for _, move := range node.moves {
+			child := node.nodes[move]
+			if child == nil || !child.lost {
+				allLost = false
+				break
+			}
+
+			if child.score > latest {
+				latest = child.score
+			}
+		}
+
+		if allLost {
+			node.lost = true
+			node.score = latest
+			return
+		}
+	}
+
+	node.score = node.evaluation
+	for _, child := range node.nodes {
+		if child == nil || child.err != nil || child.lost || child.stale {
+			continue
+		}
+
+		if child.score > node.score {
+			node.score = child.score
+		}
+	}
+}
+
+func (node *Node) print() {
+	fmt.Fprintf(os.Stderr, "T:%02d C:%02d S:%02d p/r %d,%d - %v - err %v\n", node.turn, node.choice, node.score, node.position, node.rotation, node.invalid, node.err)
+}
+
+func printTree(node *Node, depth int) {
+	if depth == 0 {
+		return
+	}
+
+	for n, node := range node.nodes {
+		if node == nil {
+			continue
+		}
+
+		for tab := 0; tab < node.turn; tab++ {
+			fmt.Fprintf(os.Stderr, "-")
+		}
+
+		fmt.Fprintf(os.Stderr, " %d] ", n)
+		node.print()
+
+		printTree(node, depth-1)
+	}
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+// PlyStats is one placement of the line the search expects to play.
+type PlyStats struct {
+	Position   int  `json:"position"`
+	Rotation   int  `json:"rotation"`
+	Score      int  `json:"score"`
+	ChainCount int  `json:"chain_count"`
+	Lost       bool `json:"lost,omitempty"`
+}
+
+// bestChild is the child the search rates highest, preferring any line that
+// survives.
+func bestChild(node *Node) *Node {
+	var best *Node = nil
+	for _, child := range node.nodes {
+		if child == nil || child.err != nil || child.invalid || child.stale {
+			continue
+		}
+
+		if best == nil || (best.lost && !child.lost) || (best.lost == child.lost && child.score > best.score) {
+			best = child
+		}
+	}
+
+	return best
+}
+
+// principalVariation follows the best children down from the move played,
+// returning the plan behind its score and the grid it ends on.
+func principalVariation(played *Node) ([]PlyStats, engine.Grid) {
+	var plies []PlyStats
+	var last *Node = played
+
+	for node := played; node != nil; node = bestChild(node) {
+		plies = append(plies, PlyStats{
+			Position:   node.position,
+			Rotation:   node.rotation,
+			Score:      node.score,
+			ChainCount: node.chainCount,
+			Lost:       node.lost,
+		})
+		last = node
+	}
+
+	return plies, last.grid
+}
+
+// Plan sums up the first plies of the principal variation as placements,
+// with the chains expected from each.
+func (stats *SearchStats) Plan(plies int) string {
+	var steps []string
+	for i, ply := range stats.Principal {
+		if i == plies {
+			break
+		}
+
+		step := fmt.Sprintf("%d,%d", ply.Position, ply.Rotation)
+		if ply.ChainCount > 0 {
+			step += fmt.Sprintf("x%d", ply.ChainCount)
+		}
+		steps = append(steps, step)
+	}
+
+	return strings.Join(steps, " ")
+}
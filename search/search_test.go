@@ -0,0 +1,1452 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edwardadd/smash_the_code/book"
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+// Shorthands keeping the grid fixtures readable
+type Grid = engine.Grid
+
+const (
+	GRID_WIDTH   = engine.GRID_WIDTH
+	GRID_HEIGHT  = engine.GRID_HEIGHT
+	EMPTY_SPACE  = engine.EMPTY_SPACE
+	SPAWN_COLUMN = engine.SPAWN_COLUMN
+	KNOWN_PAIRS  = engine.KNOWN_PAIRS
+)
+
+func TestSimulate(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+	var expectedGrid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		1, 2, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     1,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+	}
+
+	err := simulate(context, node, 0, &nextBlocks)
+	if err != nil {
+		t.Fatalf("Should not error when placing a pair into an empty grid")
+	}
+
+	if node.grid != expectedGrid {
+		t.Fatalf("Unexpected grid\n%v\n%v\n", node.grid, expectedGrid)
+	}
+}
+
+func TestSimulateAndDrop(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, 05, 04, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, 02, 04, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, 03, 03, EMPTY_SPACE, EMPTY_SPACE, 05,
+		EMPTY_SPACE, 03, 02, EMPTY_SPACE, EMPTY_SPACE, 04,
+	}
+	var expectedGrid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, 04, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, 05, 04, EMPTY_SPACE, EMPTY_SPACE, 05,
+		05, 02, 02, EMPTY_SPACE, EMPTY_SPACE, 04,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 1,
+		turn:     1,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+		{1, 2},
+	}
+
+	err := simulate(context, node, 0, &nextBlocks)
+	if err != nil {
+		t.Fatalf("Should not error when placing a pair into an empty grid")
+	}
+
+	if node.grid != expectedGrid {
+		t.Fatalf("Unexpected grid\n%v\n%v\n", node.grid, expectedGrid)
+	}
+}
+
+func TestShouldStopExploringWhenNoMoreRoom(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     0,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{3, 2},
+		{2, 1},
+		{1, 4},
+		{1, 2},
+		{5, 1},
+		{1, 2},
+		{3, 3},
+	}
+
+	explore(context, rand.Intn(22), node, 0, 8, &nextBlocks, 0)
+}
+
+func benchmarkExploreDepth3(b *testing.B) {
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     0,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{3, 2},
+		{2, 1},
+		{1, 4},
+		{1, 2},
+		{5, 1},
+		{1, 2},
+		{3, 3},
+	}
+
+	for i := 0; i < b.N; i++ {
+		exploreToDepth(node, nextBlocks, 3)
+	}
+}
+
+func benchmarkExploreDepth4(b *testing.B) {
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     0,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{3, 2},
+		{2, 1},
+		{1, 4},
+		{1, 2},
+		{5, 1},
+		{1, 2},
+		{3, 3},
+	}
+
+	for i := 0; i < b.N; i++ {
+		exploreToDepth(node, nextBlocks, 4)
+	}
+}
+
+func benchmarkExploreDepth5(b *testing.B) {
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     0,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{3, 2},
+		{2, 1},
+		{1, 4},
+		{1, 2},
+		{5, 1},
+		{1, 2},
+		{3, 3},
+	}
+
+	for i := 0; i < b.N; i++ {
+		exploreToDepth(node, nextBlocks, 5)
+	}
+}
+
+func exploreToDepth(node *Node, nextBlocks [8][2]uint8, depth int) {
+	context := newSearchContext(DefaultConfig())
+
+	for n := 0; n < 22; n++ {
+		explore(context, n, node, 0, depth, &nextBlocks, 1)
+	}
+}
+
+func BenchmarkExploreDepthFirst20000(b *testing.B) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     0,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{3, 2},
+		{2, 1},
+		{1, 4},
+		{1, 2},
+		{5, 1},
+		{1, 2},
+		{3, 3},
+	}
+
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 20000; n++ {
+			explore(context, rand.Intn(22), node, 0, 8, &nextBlocks, 0)
+		}
+	}
+}
+
+func BenchmarkExploreDepthFirst15000(b *testing.B) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     0,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{3, 2},
+		{2, 1},
+		{1, 4},
+		{1, 2},
+		{5, 1},
+		{1, 2},
+		{3, 3},
+	}
+
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 15000; n++ {
+			explore(context, rand.Intn(22), node, 0, 8, &nextBlocks, 0)
+		}
+	}
+}
+
+func BenchmarkExploreDepthFirst10000(b *testing.B) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     0,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{3, 2},
+		{2, 1},
+		{1, 4},
+		{1, 2},
+		{5, 1},
+		{1, 2},
+		{3, 3},
+	}
+
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 10000; n++ {
+			explore(context, rand.Intn(22), node, 0, 8, &nextBlocks, 0)
+		}
+	}
+}
+
+func BenchmarkExploreDepthFirst5000(b *testing.B) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid = Grid{
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+		EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE, EMPTY_SPACE,
+	}
+
+	var node *Node = &Node{
+		position: 0,
+		rotation: 0,
+		turn:     0,
+		grid:     grid,
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3},
+		{3, 2},
+		{2, 1},
+		{1, 4},
+		{1, 2},
+		{5, 1},
+		{1, 2},
+		{3, 3},
+	}
+
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 5000; n++ {
+			explore(context, rand.Intn(22), node, 0, 8, &nextBlocks, 0)
+		}
+	}
+}
+
+func TestSurvivalScoresTurnsFromRoot(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+	context.survival = true
+	context.turn = 3
+
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var pairs [8][2]uint8 = [8][2]uint8{
+		{1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2},
+	}
+
+	// A rollout numbers its sampled pairs from the horizon at turn 4
+	var node *Node = &Node{position: 0, rotation: 1, turn: 5, grid: grid}
+	if err := simulate(context, node, 4, &pairs); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := survivalScore(&context.config.Weights, &node.grid, 2); node.score != expected {
+		t.Fatalf("Expected two turns alive scoring %d, got %d", expected, node.score)
+	}
+}
+
+func TestSimulateDetectsLoss(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+
+	// Two spaces left in the spawn column, alternating so nothing clears
+	for y := 2; y < GRID_HEIGHT; y++ {
+		grid[SPAWN_COLUMN+y*GRID_WIDTH] = uint8(y%2 + 1)
+	}
+
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{4, 5}, {1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2},
+	}
+
+	var parent *Node = &Node{turn: 0, grid: grid}
+	var node *Node = &Node{position: SPAWN_COLUMN, rotation: 1, turn: 1, grid: grid, parent: parent}
+	parent.nodes[1] = node
+	parent.moves = []int{1}
+
+	err := simulate(context, node, 0, &nextBlocks)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !node.lost || node.score >= 0 {
+		t.Fatalf("Filling the spawn column should lose, got lost %v score %d", node.lost, node.score)
+	}
+
+	if !parent.lost {
+		t.Fatalf("Parent with only losing moves should be lost")
+	}
+}
+
+func TestLossPrefersLaterLines(t *testing.T) {
+	var root *Node = &Node{turn: 0}
+	var early *Node = &Node{turn: 1, parent: root, evaluation: 500}
+	var late *Node = &Node{turn: 1, parent: root, evaluation: 100}
+	var lateChild *Node = &Node{turn: 2, parent: late}
+	root.nodes[0] = early
+	root.nodes[1] = late
+	root.moves = []int{0, 1}
+	late.nodes[0] = lateChild
+	late.moves = []int{0}
+
+	early.markLost(0)
+	if root.lost {
+		t.Fatalf("Root still has a move that survives")
+	}
+
+	lateChild.markLost(0)
+	if !late.lost || !root.lost {
+		t.Fatalf("Every line loses so the root is lost")
+	}
+
+	if root.score != late.score || late.score <= early.score {
+		t.Fatalf("Losing later should score higher, early %d late %d", early.score, late.score)
+	}
+}
+
+func TestCompareGrids(t *testing.T) {
+	var predicted Grid
+	for i := range predicted {
+		predicted[i] = EMPTY_SPACE
+	}
+	predicted[GRID_WIDTH*GRID_HEIGHT-1] = 3
+
+	var skulls Grid = predicted
+	skulls[GRID_WIDTH*GRID_HEIGHT-1-GRID_WIDTH] = 0
+	skulls[GRID_WIDTH*GRID_HEIGHT-2] = 0
+
+	var diverged Grid = predicted
+	diverged[GRID_WIDTH*GRID_HEIGHT-2] = 4
+
+	if change := compareGrids(&predicted, &predicted); change != GRID_MATCHES {
+		t.Fatalf("Identical grids should match, got %d", change)
+	}
+
+	if change := compareGrids(&predicted, &skulls); change != GRID_SKULLS_ADDED {
+		t.Fatalf("Skulls on top should keep the prediction, got %d", change)
+	}
+
+	if change := compareGrids(&predicted, &diverged); change != GRID_DIVERGED {
+		t.Fatalf("Unexpected colour should diverge, got %d", change)
+	}
+}
+
+func TestReuseResimulatesAffectedNodes(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{1, 2}, {3, 4}, {1, 2}, {3, 4}, {1, 2}, {3, 4}, {1, 2}, {3, 4},
+	}
+
+	var root *Node = &Node{turn: 3, grid: grid}
+	explore(context, 0, root, 3, 2, &nextBlocks, 1)
+	child := root.nodes[0]
+
+	// Pretend a turn passed and skulls landed before the child was played
+	root.rebase(0, KNOWN_PAIRS)
+	if child.turn != 1 || child.nodes[4].turn != 2 {
+		t.Fatalf("Rebase should renumber the subtree, got %d and %d", child.turn, child.nodes[4].turn)
+	}
+
+	root.grid[5+(GRID_HEIGHT-1)*GRID_WIDTH] = 0
+	root.invalidateChildren()
+	explore(context, 0, root, 0, 1, &nextBlocks, 0)
+
+	if child.invalid || child.grid[5+(GRID_HEIGHT-1)*GRID_WIDTH] != 0 {
+		t.Fatalf("Child should have been re-simulated on top of the skull")
+	}
+
+	if !child.nodes[4].invalid {
+		t.Fatalf("Grandchildren of a changed grid are affected")
+	}
+
+	// Re-simulating onto an unchanged grid leaves the subtree alone
+	for _, grandchild := range child.nodes {
+		if grandchild != nil {
+			grandchild.invalid = false
+		}
+	}
+	child.invalid = true
+	explore(context, 0, root, 0, 1, &nextBlocks, 0)
+	if child.nodes[4].invalid {
+		t.Fatalf("Grandchildren of an unchanged grid should be kept")
+	}
+}
+
+func TestDeterminizePastKnownPairs(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{5, 3}, {3, 2}, {2, 1}, {1, 4}, {1, 2}, {5, 1}, {1, 2}, {3, 3},
+	}
+
+	// A single rollout on an empty grid cannot lose, so it makes one path
+	var root *Node = &Node{turn: 0, grid: grid}
+	explore(context, betterChoice(context, root, 0, &nextBlocks), root, 0, KNOWN_PAIRS+2, &nextBlocks, 0)
+
+	var leaf *Node = root
+	for leaf != nil && leaf.turn < KNOWN_PAIRS {
+		var deeper *Node = nil
+		for _, child := range leaf.nodes {
+			if child != nil {
+				deeper = child
+			}
+		}
+		leaf = deeper
+	}
+
+	if leaf == nil || leaf.turn != KNOWN_PAIRS {
+		t.Fatalf("Search should reach the known horizon")
+	}
+
+	for _, child := range leaf.nodes {
+		if child != nil {
+			t.Fatalf("Sampled futures should not be added to the tree")
+		}
+	}
+
+	if leaf.sampledCount != DETERMINIZATIONS {
+		t.Fatalf("Expected %d sampled futures, got %d", DETERMINIZATIONS, leaf.sampledCount)
+	}
+}
+
+func seededConfig(seed int64) Config {
+	config := DefaultConfig()
+	config.Seed = seed
+
+	// Replays must sample as much however busy the machine is
+	config.Budget = 0
+	return config
+}
+
+func TestBudgetStopsSampling(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{1, 2}, {3, 4}, {5, 1}, {2, 3}, {4, 5}, {1, 1}, {2, 2}, {3, 3},
+	}
+
+	config := DefaultConfig()
+	config.Book = false
+	config.Samples = 1000000
+	config.Budget = 20
+
+	game := NewGame(config)
+	start := time.Now()
+	game.PlayFrom(start.Add(-10*time.Millisecond), &nextColours, &grid, &grid)
+
+	// The turn started before Play was called, so there are 10ms left
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("Expected sampling to stop near the budget, took %v", elapsed)
+	}
+
+	stats := game.Stats()
+	if stats.Rollouts == 0 || stats.Rollouts >= config.Samples {
+		t.Fatalf("Expected some but not all samples, got %d", stats.Rollouts)
+	}
+}
+
+type turnInput struct {
+	nextColours [KNOWN_PAIRS][2]uint8
+	playerGrid  Grid
+	cpuGrid     Grid
+}
+
+// playGame feeds the turns to a fresh game and records the moves it makes.
+func playGame(seed int64, turns []turnInput) []Move {
+	var moves []Move
+
+	game := NewGame(seededConfig(seed))
+	for i := range turns {
+		moves = append(moves, game.Play(&turns[i].nextColours, &turns[i].playerGrid, &turns[i].cpuGrid))
+	}
+
+	return moves
+}
+
+func TestParallelGamesMatchSoloGames(t *testing.T) {
+	const TURNS int = 4
+	var empty Grid
+	for i := range empty {
+		empty[i] = EMPTY_SPACE
+	}
+
+	// Record a game where each turn's grid is the one the bot predicted
+	var turns [2][]turnInput
+	for g := range turns {
+		source := rand.New(rand.NewSource(int64(g)))
+		game := NewGame(seededConfig(int64(g)))
+		grid := empty
+
+		for turn := 0; turn < TURNS; turn++ {
+			var input turnInput = turnInput{playerGrid: grid, cpuGrid: empty}
+			for i := range input.nextColours {
+				input.nextColours[i] = [2]uint8{uint8(source.Intn(5) + 1), uint8(source.Intn(5) + 1)}
+			}
+			turns[g] = append(turns[g], input)
+
+			game.Play(&input.nextColours, &input.playerGrid, &input.cpuGrid)
+			grid = game.node.grid
+		}
+	}
+
+	var solo [2][]Move
+	for g := range turns {
+		solo[g] = playGame(int64(g), turns[g])
+	}
+
+	var parallel [2][]Move
+	var wait sync.WaitGroup
+	for g := range turns {
+		wait.Add(1)
+		go func(g int) {
+			defer wait.Done()
+			parallel[g] = playGame(int64(g), turns[g])
+		}(g)
+	}
+	wait.Wait()
+
+	for g := range turns {
+		for turn := range solo[g] {
+			if solo[g][turn] != parallel[g][turn] {
+				t.Fatalf("Game %d turn %d: alone played %v, in parallel %v", g, turn, solo[g][turn], parallel[g][turn])
+			}
+		}
+	}
+}
+
+func TestSearchStats(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{5, 3}, {3, 2}, {2, 1}, {1, 4}, {1, 2}, {5, 1}, {1, 2}, {3, 3},
+	}
+
+	config := DefaultConfig()
+	config.Samples = 200
+	config.Book = false
+	config.Budget = 0
+	game := NewGame(config)
+	move := game.Play(&nextColours, &grid, &grid)
+	stats := game.Stats()
+
+	if stats.Turn != 0 || stats.Rollouts != 200 || stats.Nodes == 0 || stats.MaxDepth != config.Depth {
+		t.Fatalf("Unexpected stats %+v", stats)
+	}
+
+	var visits int = 0
+	var played bool = false
+	for _, child := range stats.Children {
+		visits += child.Visits
+		if child.Position == move.Position && child.Rotation == move.Rotation {
+			played = true
+		}
+	}
+
+	if visits != stats.Rollouts || !played {
+		t.Fatalf("Children should account for every rollout and the move played, got %d visits", visits)
+	}
+}
+
+func TestExportTreePruning(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{5, 3}, {3, 2}, {2, 1}, {1, 4}, {1, 2}, {5, 1}, {1, 2}, {3, 3},
+	}
+
+	config := DefaultConfig()
+	config.Samples = 300
+	config.Book = false
+
+	// Every sample is counted, however slow the machine
+	config.Budget = 0
+	game := NewGame(config)
+	game.Play(&nextColours, &grid, &grid)
+
+	var depthOf func(node *ExportedNode) int
+	depthOf = func(node *ExportedNode) int {
+		var deepest int = 0
+		for _, child := range node.Children {
+			if depth := depthOf(child) + 1; depth > deepest {
+				deepest = depth
+			}
+		}
+		return deepest
+	}
+
+	full := game.ExportTree(ExportOptions{})
+	if full.Visits != config.Samples || depthOf(full) != KNOWN_PAIRS || len(full.Children) != 22 {
+		t.Fatalf("Unpruned export should hold the whole tree, got %d visits, depth %d, %d children", full.Visits, depthOf(full), len(full.Children))
+	}
+
+	pruned := game.ExportTree(ExportOptions{Depth: 2, TopK: 3})
+	if depthOf(pruned) != 2 || len(pruned.Children) != 3 {
+		t.Fatalf("Expected depth 2 and 3 children, got %d and %d", depthOf(pruned), len(pruned.Children))
+	}
+
+	for i := 1; i < len(pruned.Children); i++ {
+		if pruned.Children[i].Score > pruned.Children[i-1].Score {
+			t.Fatalf("Children should be best first")
+		}
+	}
+
+	var dot, encoded bytes.Buffer
+	if err := WriteDOT(&dot, pruned); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteJSON(&encoded, pruned); err != nil {
+		t.Fatal(err)
+	}
+
+	var nodes int = 1
+	for _, child := range pruned.Children {
+		nodes += 1 + len(child.Children)
+	}
+	if edges := strings.Count(dot.String(), "->"); edges != nodes-1 {
+		t.Fatalf("Expected %d edges, got %d\n%s", nodes-1, edges, dot.String())
+	}
+
+	var decoded ExportedNode
+	if err := json.Unmarshal(encoded.Bytes(), &decoded); err != nil || !reflect.DeepEqual(&decoded, pruned) {
+		t.Fatalf("JSON export did not round trip - %v", err)
+	}
+}
+
+func TestExportMarksStaleNodes(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{5, 3}, {3, 2}, {2, 1}, {1, 4}, {1, 2}, {5, 1}, {1, 2}, {3, 3},
+	}
+
+	config := DefaultConfig()
+	config.Samples = 50
+	config.Book = false
+	game := NewGame(config)
+	game.Play(&nextColours, &grid, &grid)
+
+	// As when the fire target changes before the next turn's search
+	game.searched.markChildrenStale()
+	exported := game.ExportTree(ExportOptions{Depth: 1})
+	for _, child := range exported.Children {
+		if !child.Stale {
+			t.Fatalf("Choice %d should be exported as stale", child.Choice)
+		}
+	}
+
+	var dot bytes.Buffer
+	if err := WriteDOT(&dot, exported); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(dot.String(), "style=dotted") != len(exported.Children) {
+		t.Fatalf("Stale nodes should be dotted\n%s", dot.String())
+	}
+}
+
+func TestPrincipalVariation(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{5, 3}, {3, 2}, {2, 1}, {1, 4}, {1, 2}, {5, 1}, {1, 2}, {3, 3},
+	}
+
+	config := DefaultConfig()
+	config.Samples = 300
+	config.Book = false
+	config.PlanPlies = 2
+	game := NewGame(config)
+	move := game.Play(&nextColours, &grid, &grid)
+	stats := game.Stats()
+	principal := stats.Principal
+
+	if len(principal) == 0 || len(principal) > KNOWN_PAIRS {
+		t.Fatalf("Unexpected plan length %d", len(principal))
+	}
+
+	if principal[0].Position != move.Position || principal[0].Rotation != move.Rotation {
+		t.Fatalf("Plan should start with the move played")
+	}
+
+	// A node scores at least as well as the line below it
+	for i := 1; i < len(principal); i++ {
+		if principal[i].Score > principal[i-1].Score {
+			t.Fatalf("Ply %d scores %d above its parent's %d", i, principal[i].Score, principal[i-1].Score)
+		}
+	}
+
+	var last *Node = game.node
+	for child := bestChild(last); child != nil; child = bestChild(child) {
+		last = child
+	}
+
+	if !reflect.DeepEqual(stats.PrincipalGrid, last.grid.Rows()) {
+		t.Fatalf("Plan should end on the grid of its last ply")
+	}
+
+	if move.Message != stats.Plan(2) || strings.Count(move.Message, ",") != 2 {
+		t.Fatalf("Message should show the first two plies, got %q", move.Message)
+	}
+}
+
+func TestPlayAimsForTimingTarget(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{5, 3}, {3, 2}, {2, 1}, {1, 4}, {1, 2}, {5, 1}, {1, 2}, {3, 3},
+	}
+
+	config := DefaultConfig()
+	config.Samples = 100
+	game := NewGame(config)
+	game.Play(&nextColours, &grid, &grid)
+	if game.Stats().Target != config.Timing.TargetChain {
+		t.Fatalf("Calm start should aim for %d, got %d", config.Timing.TargetChain, game.Stats().Target)
+	}
+
+	// A row of skulls lands on the grid the bot predicted
+	var skulls Grid = game.node.grid
+	for x := 0; x < GRID_WIDTH; x++ {
+		y := engine.HighPosition(skulls)[x]
+		skulls[x+y*GRID_WIDTH] = 0
+	}
+
+	game.Play(&nextColours, &skulls, &grid)
+	if game.Stats().Target != config.Timing.SkullChain {
+		t.Fatalf("Incoming skulls should aim for %d, got %d", config.Timing.SkullChain, game.Stats().Target)
+	}
+
+	// An opponent with almost no room left is finished off by any chain
+	var full Grid = grid
+	for i := GRID_WIDTH * 2; i < GRID_WIDTH*GRID_HEIGHT; i++ {
+		full[i] = 0
+	}
+
+	game.Play(&nextColours, &game.node.grid, &full)
+	if game.Stats().Target != 1 {
+		t.Fatalf("Nearly full opponent should fire anything, got %d", game.Stats().Target)
+	}
+}
+
+func TestEvaluateMetamorphic(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+	context.target = 2
+	rng := rand.New(rand.NewSource(17))
+
+	score := func(grid Grid, position int, rotation int, colours [2]uint8) (int, bool) {
+		resolution, err := engine.Place(&grid, position, rotation, colours)
+		if err != nil {
+			return 0, false
+		}
+		heights := engine.HighPosition(grid)
+		return evaluate(context, &grid, &heights, resolution, 0, false), true
+	}
+
+	for game := 0; game < 300; game++ {
+		var grid Grid
+		for x := 0; x < GRID_WIDTH; x++ {
+			height := rng.Intn(GRID_HEIGHT - 2)
+			for y := 0; y < GRID_HEIGHT; y++ {
+				grid[x+y*GRID_WIDTH] = EMPTY_SPACE
+				if y >= GRID_HEIGHT-height {
+					grid[x+y*GRID_WIDTH] = uint8(rng.Intn(6))
+				}
+			}
+		}
+
+		var permutation [6]uint8
+		for i, colour := range rng.Perm(5) {
+			permutation[i+1] = uint8(colour + 1)
+		}
+
+		var permuted, mirrored Grid
+		for y := 0; y < GRID_HEIGHT; y++ {
+			for x := 0; x < GRID_WIDTH; x++ {
+				cell := grid[x+y*GRID_WIDTH]
+				permuted[x+y*GRID_WIDTH] = cell
+				if cell != EMPTY_SPACE {
+					permuted[x+y*GRID_WIDTH] = permutation[cell]
+				}
+				mirrored[GRID_WIDTH-1-x+y*GRID_WIDTH] = cell
+			}
+		}
+
+		colours := [2]uint8{uint8(rng.Intn(5) + 1), uint8(rng.Intn(5) + 1)}
+		position, rotation := engine.ChoiceToAction(rng.Intn(len(engine.Choices)))
+
+		original, ok := score(grid, position, rotation, colours)
+		if !ok {
+			continue
+		}
+
+		if recoloured, _ := score(permuted, position, rotation, [2]uint8{permutation[colours[0]], permutation[colours[1]]}); recoloured != original {
+			t.Fatalf("Game %d scored %d, %d once colours are permuted", game, original, recoloured)
+		}
+
+		// Mirrored, a pair pointing right points left
+		mirroredRotation := rotation
+		if rotation == 0 || rotation == 2 {
+			mirroredRotation = 2 - rotation
+		}
+		if reflected, ok := score(mirrored, GRID_WIDTH-1-position, mirroredRotation, colours); ok && reflected != original {
+			t.Fatalf("Game %d scored %d, %d once mirrored", game, original, reflected)
+		}
+	}
+}
+
+func TestAnalyse(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{1, 2}, {3, 4}, {5, 5}, {1, 1}, {2, 3}, {4, 5}, {1, 3}, {2, 4},
+	}
+
+	config := seededConfig(3)
+	config.Samples = 300
+	config.Book = false
+	game := NewGame(config)
+	move := game.Play(&nextColours, &grid, &grid)
+
+	analysis := game.Analyse()
+	if len(analysis) != len(engine.Choices) {
+		t.Fatalf("Expected every choice on an empty grid, got %d", len(analysis))
+	}
+
+	var visits int = 0
+	var played bool = false
+	for _, choice := range analysis {
+		if choice.Duplicate {
+			continue
+		}
+
+		visits += choice.Visits
+		if choice.Visits == 0 {
+			continue
+		}
+
+		if choice.Mean > choice.Max {
+			t.Fatalf("Choice %d has a mean of %d above its best of %d", choice.Choice, choice.Mean, choice.Max)
+		}
+
+		if choice.Principal[0].Position != choice.Position || choice.Principal[0].Rotation != choice.Rotation {
+			t.Fatalf("Choice %d's plan starts elsewhere", choice.Choice)
+		}
+
+		if choice.Position == move.Position && choice.Rotation == move.Rotation {
+			played = true
+		}
+	}
+
+	if visits != config.Samples || !played {
+		t.Fatalf("Expected %d visits including the move played, got %d", config.Samples, visits)
+	}
+}
+
+func TestAnalyseSameGridChoices(t *testing.T) {
+	var grid Grid
+	for i := range grid {
+		grid[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{3, 3}, {1, 2}, {5, 5}, {1, 1}, {2, 3}, {4, 5}, {1, 3}, {2, 4},
+	}
+
+	config := seededConfig(3)
+	config.Samples = 300
+	config.Book = false
+	game := NewGame(config)
+	game.Play(&nextColours, &grid, &grid)
+
+	analysis := game.Analyse()
+	byChoice := map[int]ChoiceAnalysis{}
+	var explored int = 0
+	for _, choice := range analysis {
+		byChoice[choice.Choice] = choice
+		if !choice.Duplicate {
+			explored++
+		}
+	}
+
+	if explored != len(engine.GenerateMoves(&grid, nextColours[0])) {
+		t.Fatalf("Expected one explored choice per distinct grid, got %d", explored)
+	}
+
+	for _, choice := range analysis {
+		if !choice.Duplicate {
+			continue
+		}
+
+		twin, ok := byChoice[choice.SameAs]
+		if !ok || twin.Duplicate || twin.Choice >= choice.Choice {
+			t.Fatalf("Choice %d should point at an earlier explored choice, got %d", choice.Choice, choice.SameAs)
+		}
+
+		if choice.Visits != twin.Visits || choice.Max != twin.Max || choice.Mean != twin.Mean {
+			t.Fatalf("Choice %d should share choice %d's ratings", choice.Choice, twin.Choice)
+		}
+	}
+}
+
+func TestPlayFollowsOpeningBook(t *testing.T) {
+	var empty Grid
+	for i := range empty {
+		empty[i] = EMPTY_SPACE
+	}
+	var queue [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{4, 2}, {2, 3}, {5, 5}, {1, 1}, {2, 3}, {4, 5}, {1, 3}, {2, 4},
+	}
+
+	line := book.Lookup(&queue)
+	if line == nil {
+		t.Fatalf("No opening for the queue")
+	}
+
+	config := seededConfig(5)
+	config.Samples = 300
+
+	// play makes the move on the grid and moves the queue along
+	play := func(grid *Grid, nextColours *[KNOWN_PAIRS][2]uint8, move Move) {
+		engine.Place(grid, move.Position, move.Rotation, nextColours[0])
+		copy(nextColours[:], nextColours[1:])
+		nextColours[KNOWN_PAIRS-1] = [2]uint8{1, 2}
+	}
+
+	game := NewGame(config)
+	grid, nextColours := empty, queue
+	for turn := 0; turn < len(line); turn++ {
+		move := game.Play(&nextColours, &grid, &empty)
+		if move.Position != line[turn].Position || move.Rotation != line[turn].Rotation || move.Message != "By the book" {
+			t.Fatalf("Turn %d played %+v, the book says %+v", turn, move, line[turn])
+		}
+		if game.Stats().Rollouts != 0 {
+			t.Fatalf("Turn %d searched %d rollouts for a book move", turn, game.Stats().Rollouts)
+		}
+		play(&grid, &nextColours, move)
+	}
+
+	// A skull landing knocks the game off the book
+	game = NewGame(config)
+	grid, nextColours = empty, queue
+	play(&grid, &nextColours, game.Play(&nextColours, &grid, &empty))
+
+	y := engine.HighPosition(grid)[GRID_WIDTH-1]
+	grid[GRID_WIDTH-1+y*GRID_WIDTH] = 0
+
+	if move := game.Play(&nextColours, &grid, &empty); move.Message == "By the book" || game.opening != nil {
+		t.Fatalf("Expected the book to be left behind")
+	}
+}
+
+func TestEvaluateRewardsTemplates(t *testing.T) {
+	// Stairs missing the top of their last two columns
+	grid, err := engine.GridFromRows([]string{
+		"32....",
+		"321...",
+		"321...",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	score := func(config Config, position int, rotation int) int {
+		context := newSearchContext(config)
+		var placed Grid = grid
+		resolution, err := engine.Place(&placed, position, rotation, [2]uint8{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		heights := engine.HighPosition(placed)
+		return evaluate(context, &placed, &heights, resolution, 0, false)
+	}
+
+	config := DefaultConfig()
+	extends, beside := score(config, 2, 1), score(config, 3, 1)
+	if extends <= beside {
+		t.Fatalf("Extending the stairs scored %d, beside it %d", extends, beside)
+	}
+
+	// Beside the stairs keeps the grid tidier, so only the template term
+	// puts the stairs first
+	config.Weights.Template = 0
+	if extends, beside := score(config, 2, 1), score(config, 3, 1); extends >= beside {
+		t.Fatalf("Without templates extending scored %d, beside it %d", extends, beside)
+	}
+}
+
+func TestLuckOfTheDrawPicksPlayableChildren(t *testing.T) {
+	game := NewGame(DefaultConfig())
+
+	// A lone child in the last slot used to index past the children
+	game.node.nodes[21] = &Node{choice: 21}
+	game.node.nodes[3] = &Node{choice: 3, lost: true}
+	for i := 0; i < 50; i++ {
+		if picked := game.luckOfTheDraw(); picked != game.node.nodes[21] {
+			t.Fatalf("Expected the only playable child, got %v", picked)
+		}
+	}
+
+	game.node.nodes[0] = &Node{choice: 0}
+	seen := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		seen[game.luckOfTheDraw().choice] = true
+	}
+	if len(seen) != 2 || !seen[0] || !seen[21] {
+		t.Fatalf("Expected both playable children to be picked, got %v", seen)
+	}
+}
+
+func TestStaleScoresAreRecomputed(t *testing.T) {
+	grid, err := engine.GridFromRows([]string{"11...."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nextBlocks [8][2]uint8 = [8][2]uint8{
+		{1, 1}, {2, 3}, {4, 5}, {2, 3}, {4, 5}, {2, 3}, {4, 5}, {2, 3},
+	}
+
+	// Completing the row of four fires a one step chain
+	var choice int = -1
+	for i, action := range engine.Choices {
+		if action == [2]int{2, 0} {
+			choice = i
+		}
+	}
+
+	scoreFor := func(context *searchContext) int {
+		var root *Node = &Node{grid: grid}
+		explore(context, choice, root, 0, 1, &nextBlocks, 1)
+		return root.nodes[choice].score
+	}
+
+	context := newSearchContext(DefaultConfig())
+	context.target = 4
+	var root *Node = &Node{grid: grid}
+	explore(context, choice, root, 0, 1, &nextBlocks, 1)
+	child := root.nodes[choice]
+	building := child.score
+
+	// Next turn the policy wants any chain fired
+	context.target = 1
+	fresh := scoreFor(context)
+	if fresh == building {
+		t.Fatalf("Scores should depend on the target, both %d", fresh)
+	}
+
+	root.markChildrenStale()
+	explore(context, choice, root, 0, 1, &nextBlocks, 0)
+	if child.stale || child.score != fresh {
+		t.Fatalf("Stale child should be rescored to %d, got %d", fresh, child.score)
+	}
+}
+
+func TestLosingFuturesLowerScore(t *testing.T) {
+	context := newSearchContext(DefaultConfig())
+
+	// Only the top of the spawn column is left, so any future tops out
+	var grid Grid
+	for i := range grid {
+		grid[i] = 0
+	}
+	grid[SPAWN_COLUMN] = EMPTY_SPACE
+	grid[SPAWN_COLUMN+GRID_WIDTH] = EMPTY_SPACE
+
+	var root *Node = &Node{turn: 0, grid: grid, score: 500}
+	var leaf *Node = &Node{turn: KNOWN_PAIRS, grid: grid, parent: root, score: 500, evaluation: 500}
+	root.nodes[0] = leaf
+
+	leaf.determinize(context, 2, 0)
+
+	if leaf.score >= 0 {
+		t.Fatalf("Losing futures should pull the score down, got %d", leaf.score)
+	}
+	if root.score != root.evaluation {
+		t.Fatalf("Parent should no longer be backed by the leaf, got %d", root.score)
+	}
+}
+
+func TestRebaseDropsSampledScores(t *testing.T) {
+	// A line down to the known horizon, scored over sampled futures there
+	var line []*Node = []*Node{{turn: 0, evaluation: 100}}
+	for turn := 1; turn <= KNOWN_PAIRS; turn++ {
+		parent := line[turn-1]
+		node := &Node{turn: turn, parent: parent, evaluation: 100 + turn}
+		parent.nodes[0] = node
+		line = append(line, node)
+	}
+	leaf := line[KNOWN_PAIRS]
+	leaf.sampledScore, leaf.sampledCount = 1800, 2
+	for _, node := range line {
+		node.score = 900
+	}
+
+	line[0].rebase(0, KNOWN_PAIRS)
+	if leaf.stale || line[0].score != 900 {
+		t.Fatalf("Horizon leaf should keep its samples, got stale %v and score %d", leaf.stale, line[0].score)
+	}
+
+	// A turn later the leaf's pair is known
+	root := line[1]
+	root.parent = nil
+	root.rebase(1, 1+KNOWN_PAIRS)
+	if !leaf.stale || leaf.sampledCount != 0 {
+		t.Fatalf("Leaf inside the known pairs should be rescored")
+	}
+
+	parent := line[KNOWN_PAIRS-1]
+	if parent.score != parent.evaluation || root.score != parent.evaluation {
+		t.Fatalf("Ancestors should drop the sampled score, got %d and %d", parent.score, root.score)
+	}
+}
+
+func TestSituationSeesOpponentChain(t *testing.T) {
+	var empty Grid
+	for i := range empty {
+		empty[i] = EMPTY_SPACE
+	}
+
+	// Dropping the ones lets the twos meet
+	opponent, err := engine.GridFromRows([]string{
+		".2....",
+		"24....",
+		"24....",
+		"21....",
+		"11....",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	if chain := situation(&config, &empty, &opponent, 0, [2]uint8{1, 1}).OpponentChain; chain != 2 {
+		t.Fatalf("Expected the opponent's chain of 2, got %d", chain)
+	}
+	if chain := situation(&config, &empty, &opponent, 0, [2]uint8{3, 5}).OpponentChain; chain != 0 {
+		t.Fatalf("Pair that cannot clear should not threaten, got %d", chain)
+	}
+
+	config.Timing.ThreatChain = 2
+	if target := config.Timing.Target(situation(&config, &empty, &opponent, 0, [2]uint8{1, 1})); target != config.Timing.SkullChain {
+		t.Fatalf("Threatened target should be %d, got %d", config.Timing.SkullChain, target)
+	}
+}
+
+func TestNewGameAtTurn(t *testing.T) {
+	var empty Grid
+	for i := range empty {
+		empty[i] = EMPTY_SPACE
+	}
+	var queue [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{4, 2}, {2, 3}, {5, 5}, {1, 1}, {2, 3}, {4, 5}, {1, 3}, {2, 4},
+	}
+
+	config := seededConfig(5)
+	config.Samples = 100
+
+	// Joined mid game the book, which only opens a game, is not consulted
+	game := NewGameAt(config, 3)
+	move := game.Play(&queue, &empty, &empty)
+	if game.Stats().Turn != 3 || game.searched.turn != 3 {
+		t.Fatalf("Expected the search at turn 3, got %d and %d", game.Stats().Turn, game.searched.turn)
+	}
+	if move.Message == "By the book" {
+		t.Fatalf("Book played at turn 3")
+	}
+	if game.searched.grid != empty {
+		t.Fatalf("Root should start from the observed grid")
+	}
+}
+
+// BenchmarkPlayTemplates times a turn at the default budget on a grid being
+// built, with and without the template term, to keep its cost in view.
+func BenchmarkPlayTemplates(b *testing.B) {
+	grid, _ := engine.GridFromRows([]string{
+		"3.....",
+		"32.4..",
+		"321.5.",
+		"321145",
+	})
+	var empty Grid
+	for i := range empty {
+		empty[i] = EMPTY_SPACE
+	}
+	var nextColours [KNOWN_PAIRS][2]uint8 = [KNOWN_PAIRS][2]uint8{
+		{5, 3}, {3, 2}, {2, 1}, {1, 4}, {1, 2}, {5, 1}, {1, 2}, {3, 3},
+	}
+
+	for _, weight := range []int{0, DefaultConfig().Weights.Template} {
+		b.Run(fmt.Sprintf("template=%d", weight), func(b *testing.B) {
+			config := seededConfig(1)
+			config.Weights.Template = weight
+			for i := 0; i < b.N; i++ {
+				game := NewGame(config)
+				game.Play(&nextColours, &grid, &empty)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChildStats describes one of the choices open at the root.
+type ChildStats struct {
+	Position int  `json:"position"`
+	Rotation int  `json:"rotation"`
+	Visits   int  `json:"visits"`
+	Score    int  `json:"score"`
+	Lost     bool `json:"lost,omitempty"`
+}
+
+// SearchStats records what the search did during a turn.
+type SearchStats struct {
+	Turn     int `json:"turn"`
+	Rollouts int `json:"rollouts"`
+
+	// Chain length the fire timing policy aimed for
+	Target int `json:"target"`
+
+	// Nodes added to the tree and futures sampled past the known pairs
+	Nodes   int `json:"nodes"`
+	Sampled int `json:"sampled"`
+
+	// Deepest ply simulated, counting sampled futures
+	MaxDepth int `json:"max_depth"`
+
+	// Parse time is filled in by whoever reads the input
+	Parse  time.Duration `json:"parse_ns"`
+	Search time.Duration `json:"search_ns"`
+
+	Children []ChildStats `json:"children"`
+
+	// The line the search expects to follow from the move played, and
+	// the grid at its end
+	Principal     []PlyStats `json:"principal"`
+	PrincipalGrid []string   `json:"principal_grid"`
+}
+
+func (stats *SearchStats) reachedDepth(depth int) {
+	if depth > stats.MaxDepth {
+		stats.MaxDepth = depth
+	}
+}
+
+// String is the one line summary written to stderr each turn.
+func (stats *SearchStats) String() string {
+	var best *ChildStats = nil
+	var visited int = 0
+	for i := range stats.Children {
+		child := &stats.Children[i]
+		if child.Visits > 0 {
+			visited++
+		}
+		if !child.Lost && (best == nil || child.Score > best.Score) {
+			best = child
+		}
+	}
+
+	var summary string = fmt.Sprintf("turn %d target %d rollouts %d nodes %d sampled %d depth %d children %d parse %v search %v",
+		stats.Turn, stats.Target, stats.Rollouts, stats.Nodes, stats.Sampled, stats.MaxDepth,
+		visited,
+		stats.Parse.Round(time.Microsecond), stats.Search.Round(time.Microsecond))
+
+	if best != nil {
+		summary += fmt.Sprintf(" best %d,%d score %d visits %d", best.Position, best.Rotation, best.Score, best.Visits)
+	}
+
+	if len(stats.Principal) > 0 {
+		summary += " plan " + stats.Plan(len(stats.Principal))
+	}
+
+	return summary
+}
@@ -0,0 +1,200 @@
+// Package solver finds the best way to place a fixed sequence of pairs,
+// for chain puzzles set apart from the live game.
+//
+// The search is depth first over every distinct placement, pruned by an
+// optimistic bound on what the blocks left could still score and by
+// skipping grids already reached at the same ply with at least as much to
+// show for it.
+package solver
+
+import (
+	"sort"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+// What a line of placements is worth
+type Objective int
+
+const (
+	// The longest chain set off by any placement of the line
+	CHAIN Objective = iota
+
+	// The official score of every chain of the line added up
+	SCORE
+)
+
+// Placement is one move of a line and the chain it sets off.
+type Placement struct {
+	Position   int `json:"position"`
+	Rotation   int `json:"rotation"`
+	ChainCount int `json:"chain_count"`
+	Score      int `json:"score"`
+}
+
+// Solution is the best line found and what the search did to find it.
+type Solution struct {
+	Line  []Placement `json:"line"`
+	Value int         `json:"value"`
+
+	// Positions searched and the ones cut off without looking at their moves
+	Nodes  int `json:"nodes"`
+	Pruned int `json:"pruned"`
+}
+
+type seenKey struct {
+	grid engine.Grid
+	ply  int
+}
+
+type solver struct {
+	pairs     [][2]uint8
+	objective Objective
+
+	best Solution
+	line []Placement
+	seen map[seenKey]int
+}
+
+// Solve returns the line of placements for pairs, in order, that is worth
+// the most under the objective. Lines stop early when no move is left that
+// keeps the game alive.
+func Solve(grid *engine.Grid, pairs [][2]uint8, objective Objective) Solution {
+	s := &solver{
+		pairs:     pairs,
+		objective: objective,
+		best:      Solution{Value: -1},
+		seen:      map[seenKey]int{},
+	}
+
+	s.search(grid, 0, 0)
+
+	return s.best
+}
+
+// combine is the value of a line once placement is added to it.
+func (s *solver) combine(value int, placement *Placement) int {
+	if s.objective == CHAIN {
+		if placement.ChainCount > value {
+			return placement.ChainCount
+		}
+		return value
+	}
+
+	return value + placement.Score
+}
+
+// bound is the most a line at this ply could end up worth. Every chain step
+// clears at least four blocks of one colour, so the blocks left limit the
+// steps. Scores are highest when those steps form a single chain that
+// clears four blocks a step and saves the rest for its last step, with the
+// group bonus of a step at most its blocks less three.
+func (s *solver) bound(grid *engine.Grid, ply int, value int) int {
+	var counts [6]int
+	for _, cell := range grid {
+		if cell >= 1 && cell <= 5 {
+			counts[cell]++
+		}
+	}
+	for _, pair := range s.pairs[ply:] {
+		counts[pair[0]]++
+		counts[pair[1]]++
+	}
+
+	var steps, blocks, colours int = 0, 0, 0
+	for colour := 1; colour <= 5; colour++ {
+		if counts[colour] >= 4 {
+			steps += counts[colour] / 4
+			blocks += counts[colour]
+			colours++
+		}
+	}
+
+	if s.objective == CHAIN {
+		if steps > value {
+			return steps
+		}
+		return value
+	}
+
+	var best, early int = 0, 0
+	for step := 1; step <= steps; step++ {
+		rest := blocks - 4*(step-1)
+
+		var last int = 10 * rest * clamp(engine.ChainPowerForStep(step)+engine.ColourBonus(min(colours, rest/4))+rest-3)
+		if early+last > best {
+			best = early + last
+		}
+
+		early += 10 * 4 * clamp(engine.ChainPowerForStep(step)+1)
+	}
+
+	return value + best
+}
+
+// clamp keeps a multiplier within the rules' limits.
+func clamp(multiplier int) int {
+	if multiplier < 1 {
+		return 1
+	}
+	if multiplier > 999 {
+		return 999
+	}
+	return multiplier
+}
+
+func (s *solver) search(grid *engine.Grid, ply int, value int) {
+	s.best.Nodes++
+
+	if value > s.best.Value || (value == s.best.Value && len(s.line) > len(s.best.Line)) {
+		s.best.Value = value
+		s.best.Line = append([]Placement(nil), s.line...)
+	}
+
+	if ply == len(s.pairs) {
+		return
+	}
+
+	if s.bound(grid, ply, value) <= s.best.Value && len(s.best.Line) == len(s.pairs) {
+		s.best.Pruned++
+		return
+	}
+
+	key := seenKey{*grid, ply}
+	if previous, ok := s.seen[key]; ok && previous >= value {
+		s.best.Pruned++
+		return
+	}
+	s.seen[key] = value
+
+	type child struct {
+		grid      engine.Grid
+		placement Placement
+		value     int
+	}
+
+	var children []child
+	for _, choice := range engine.GenerateMoves(grid, s.pairs[ply]) {
+		position, rotation := engine.ChoiceToAction(choice)
+
+		var next engine.Grid = *grid
+		resolution, err := engine.Place(&next, position, rotation, s.pairs[ply])
+		if err != nil || engine.IsLost(&next) {
+			continue
+		}
+
+		placement := Placement{position, rotation, resolution.ChainCount, resolution.Score}
+		children = append(children, child{next, placement, s.combine(value, &placement)})
+	}
+
+	// The most promising moves first so the bound bites early
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].value > children[j].value
+	})
+
+	for i := range children {
+		s.line = append(s.line[:ply], children[i].placement)
+		s.search(&children[i].grid, ply+1, children[i].value)
+	}
+	s.line = s.line[:ply]
+}
@@ -0,0 +1,122 @@
+package solver
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+// exhaustive tries every line without pruning.
+func exhaustive(grid *engine.Grid, pairs [][2]uint8, objective Objective, value int) int {
+	if len(pairs) == 0 {
+		return value
+	}
+
+	var best int = value
+	s := &solver{objective: objective}
+	for _, choice := range engine.GenerateMoves(grid, pairs[0]) {
+		position, rotation := engine.ChoiceToAction(choice)
+
+		var next engine.Grid = *grid
+		resolution, err := engine.Place(&next, position, rotation, pairs[0])
+		if err != nil || engine.IsLost(&next) {
+			continue
+		}
+
+		placement := Placement{position, rotation, resolution.ChainCount, resolution.Score}
+		if v := exhaustive(&next, pairs[1:], objective, s.combine(value, &placement)); v > best {
+			best = v
+		}
+	}
+
+	return best
+}
+
+func TestSolveCascade(t *testing.T) {
+	grid, _ := engine.GridFromRows([]string{"1..2.2", "11.022"})
+
+	solution := Solve(&grid, [][2]uint8{{1, 4}}, CHAIN)
+	if solution.Value != 2 || len(solution.Line) != 1 || solution.Line[0].Score != 360 {
+		t.Fatalf("Expected the two step chain, got %+v", solution)
+	}
+}
+
+// settle lets the grid fall and clears groups until it is one that could
+// come up in a game.
+func settle(grid *engine.Grid) {
+	for {
+		grid.ApplyGravity()
+
+		var cleared bool = false
+		for _, component := range engine.Components(grid) {
+			if component.Size < 4 {
+				continue
+			}
+
+			for i := range grid {
+				if component.Members.Has(i) || component.Skulls.Has(i) {
+					grid[i] = engine.EMPTY_SPACE
+				}
+			}
+			cleared = true
+		}
+
+		if !cleared {
+			return
+		}
+	}
+}
+
+func TestSolveMatchesExhaustiveSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+
+	for puzzle := 0; puzzle < 20; puzzle++ {
+		var rows []string
+		for y := 0; y < 6; y++ {
+			row := []byte("......")
+			for x := range row {
+				if y >= 2 || rng.Intn(3) == 0 {
+					row[x] = byte('0' + rng.Intn(6))
+				}
+			}
+			rows = append(rows, string(row))
+		}
+
+		grid, _ := engine.GridFromRows(rows)
+		settle(&grid)
+		for _, component := range engine.Components(&grid) {
+			if component.Size >= 4 {
+				t.Fatalf("Puzzle %d still has a group of %d", puzzle, component.Size)
+			}
+		}
+
+		var pairs [][2]uint8
+		for i := 0; i < 3; i++ {
+			pairs = append(pairs, [2]uint8{uint8(rng.Intn(5) + 1), uint8(rng.Intn(5) + 1)})
+		}
+
+		for _, objective := range []Objective{CHAIN, SCORE} {
+			solution := Solve(&grid, pairs, objective)
+			expected := exhaustive(&grid, pairs, objective, 0)
+			if solution.Value != expected {
+				t.Fatalf("Puzzle %d objective %d: solved for %d, best is %d", puzzle, objective, solution.Value, expected)
+			}
+
+			// Replaying the line must give what was claimed
+			var replayed engine.Grid = grid
+			var value int = 0
+			for i, placement := range solution.Line {
+				resolution, err := engine.Place(&replayed, placement.Position, placement.Rotation, pairs[i])
+				if err != nil {
+					t.Fatalf("Puzzle %d: line is illegal at ply %d", puzzle, i)
+				}
+				played := Placement{placement.Position, placement.Rotation, resolution.ChainCount, resolution.Score}
+				value = (&solver{objective: objective}).combine(value, &played)
+			}
+			if value != solution.Value {
+				t.Fatalf("Puzzle %d: line is worth %d, claimed %d", puzzle, value, solution.Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Package templates describes chain shapes strong players build towards and
+// measures how far a grid has come towards each of them.
+//
+// A template is a few rows at the bottom of the grid, written from the top.
+// Letters stand for colours: every cell with the same letter must hold the
+// same colour and different letters different colours. Dots are left to
+// whatever else is built.
+package templates
+
+import (
+	"math/bits"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+// Template is a chain shape and the chain it fires once complete.
+type Template struct {
+	Name  string
+	Rows  []string
+	Chain int
+}
+
+// Library holds the shapes the bot builds towards.
+var Library []Template = []Template{
+	{
+		// A pair sandwiched in the first column falls away to let the top
+		// block join its colour below
+		Name:  "sandwich",
+		Rows:  []string{"A.", "BB", "BA", "AA"},
+		Chain: 2,
+	},
+	{
+		// The block folded over the top left drops into the L beside it
+		Name:  "gtr",
+		Rows:  []string{"A..", "BBA", "BAA"},
+		Chain: 2,
+	},
+	{
+		// Each column's top block steps down into the next column
+		Name:  "stairs",
+		Rows:  []string{".CB", "CBA", "CBA", "CBA"},
+		Chain: 3,
+	},
+}
+
+// Fit is how well a grid matches a template at one place.
+type Fit struct {
+	Template *Template
+	Offset   int
+	Mirrored bool
+
+	// Template cells holding the colour their letter was given, holding
+	// something else, and in all
+	Filled    int
+	Conflicts int
+	Cells     int
+}
+
+// Progress is the share of the template built, out of 100, with each
+// conflicting cell costing two filled ones.
+func (fit *Fit) Progress() int {
+	if fit.Cells == 0 {
+		return 0
+	}
+
+	progress := (fit.Filled - 2*fit.Conflicts) * 100 / fit.Cells
+	if progress < 0 {
+		return 0
+	}
+	return progress
+}
+
+// Value is the progress weighted by the chain the template fires, so longer
+// chains are worth building towards from further off.
+func (fit *Fit) Value() int {
+	if fit.Template == nil {
+		return 0
+	}
+
+	return fit.Progress() * fit.Template.Chain
+}
+
+// Templates are matched as bit masks over the cells of this many bottom
+// rows, so no template may be taller
+const (
+	MASK_ROWS   int = 10
+	MAX_LETTERS int = 5
+)
+
+const maskBase int = (engine.GRID_HEIGHT - MASK_ROWS) * engine.GRID_WIDTH
+
+// placement is a template laid at one offset, as written or mirrored, as the
+// masks of the cells it covers, of each letter's cells and of the row above
+// it. Letters are numbered in the order they first appear.
+type placement struct {
+	template *Template
+	offset   int
+	mirrored bool
+
+	cells   uint64
+	above   uint64
+	size    int
+	letters [MAX_LETTERS]uint64
+	count   int
+}
+
+var placements []placement
+
+// Rows of the tallest template, the masks cover one more to see the row
+// above it
+var tallest int = 0
+
+func init() {
+	for i := range Library {
+		template := &Library[i]
+		width := len(template.Rows[0])
+		top := engine.GRID_HEIGHT - len(template.Rows)
+
+		if len(template.Rows) > tallest {
+			tallest = len(template.Rows)
+		}
+
+		for offset := 0; offset+width <= engine.GRID_WIDTH; offset++ {
+			for _, mirrored := range [2]bool{false, true} {
+				placements = append(placements, compile(template, offset, mirrored, width, top))
+			}
+		}
+	}
+}
+
+func compile(template *Template, offset int, mirrored bool, width int, top int) placement {
+	var p placement = placement{template: template, offset: offset, mirrored: mirrored}
+	var numbers [26]int
+
+	for column := 0; column < width; column++ {
+		p.above |= 1 << (offset + column + (top-1)*engine.GRID_WIDTH - maskBase)
+	}
+
+	for row, text := range template.Rows {
+		for column := 0; column < width; column++ {
+			if text[column] < 'A' || text[column] > 'Z' {
+				continue
+			}
+
+			letter := text[column] - 'A'
+			if numbers[letter] == 0 {
+				p.count++
+				numbers[letter] = p.count
+			}
+
+			x := offset + column
+			if mirrored {
+				x = offset + width - 1 - column
+			}
+
+			var bit uint64 = 1 << (x + (top+row)*engine.GRID_WIDTH - maskBase)
+			p.cells |= bit
+			p.letters[numbers[letter]-1] |= bit
+			p.size++
+		}
+	}
+
+	return p
+}
+
+// masks returns the cells of the bottom rows holding a block, and those
+// holding each colour.
+func masks(grid *engine.Grid, rows int) (uint64, [6]uint64) {
+	var occupied uint64 = 0
+	var colours [6]uint64
+
+	for i := (engine.GRID_HEIGHT - rows) * engine.GRID_WIDTH; i < engine.GRID_WIDTH*engine.GRID_HEIGHT; i++ {
+		block := grid[i]
+		if block == engine.EMPTY_SPACE {
+			continue
+		}
+
+		var bit uint64 = 1 << (i - maskBase)
+		occupied |= bit
+		if block <= 5 {
+			colours[block] |= bit
+		}
+	}
+
+	return occupied, colours
+}
+
+// Match returns the best fit of the template along the bottom of the grid,
+// trying every column it fits in, as written and mirrored.
+func Match(grid *engine.Grid, template *Template) Fit {
+	var best Fit = Fit{Template: template}
+	occupied, colours := masks(grid, len(template.Rows))
+
+	for i := range placements {
+		if placements[i].template != template {
+			continue
+		}
+
+		fit := matchAt(&placements[i], occupied, &colours)
+		if fit.Progress() > best.Progress() {
+			best = fit
+		}
+	}
+
+	return best
+}
+
+// Best returns the library template the grid has come furthest towards,
+// weighing longer chains higher. Only places whose columns are no taller than
+// the template count, as blocks stacked above a template bury it, so the
+// templates fade out column by column as the grid grows.
+func Best(grid *engine.Grid) Fit {
+	var best Fit
+
+	occupied, colours := masks(grid, tallest+1)
+	var value int = -1
+	for i := range placements {
+		p := &placements[i]
+
+		// Columns are packed, so a taller one has a block in the row above
+		if occupied&p.above != 0 {
+			continue
+		}
+
+		// No better than the best so far even if every block agreed
+		if bits.OnesCount64(occupied&p.cells)*100/p.size*p.template.Chain <= value {
+			continue
+		}
+
+		fit := matchAt(p, occupied, &colours)
+		if fit.Value() > value {
+			best = fit
+			value = fit.Value()
+		}
+	}
+	return best
+}
+
+// matchAt gives the letters the distinct colours that agree with the most
+// cells and counts those cells. Every other block in the template's cells,
+// skulls included, is in the way.
+func matchAt(p *placement, occupied uint64, colours *[6]uint64) Fit {
+	var fit Fit = Fit{Template: p.template, Offset: p.offset, Mirrored: p.mirrored, Cells: p.size}
+
+	var held [MAX_LETTERS][6]int
+	for letter := 0; letter < p.count; letter++ {
+		for c := 1; c <= 5; c++ {
+			held[letter][c] = bits.OnesCount64(colours[c] & p.letters[letter])
+		}
+	}
+
+	var taken [6]bool
+	fit.Filled = assign(&held, p.count, 0, &taken)
+	fit.Conflicts = bits.OnesCount64(occupied&p.cells) - fit.Filled
+
+	return fit
+}
+
+// assign returns the most cells the letters from letter on can agree with,
+// each taking a colour no other letter has. Trying every way keeps the
+// result the same however the colours are numbered.
+func assign(held *[MAX_LETTERS][6]int, count int, letter int, taken *[6]bool) int {
+	if letter == count {
+		return 0
+	}
+
+	// Leaving the letter without a colour, as when none of its cells agree
+	var best int = assign(held, count, letter+1, taken)
+	for c := 1; c <= 5; c++ {
+		if taken[c] || held[letter][c] == 0 {
+			continue
+		}
+
+		taken[c] = true
+		if filled := held[letter][c] + assign(held, count, letter+1, taken); filled > best {
+			best = filled
+		}
+		taken[c] = false
+	}
+
+	return best
+}
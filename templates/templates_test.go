@@ -0,0 +1,136 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/edwardadd/smash_the_code/engine"
+)
+
+// build writes the template into the bottom of an empty grid, letter A as
+// colour 1 and so on.
+func build(template *Template, offset int) engine.Grid {
+	var rows []string
+	for _, text := range template.Rows {
+		row := []byte("......")
+		for column := 0; column < len(text); column++ {
+			if text[column] >= 'A' && text[column] <= 'Z' {
+				row[offset+column] = '1' + text[column] - 'A'
+			}
+		}
+		rows = append(rows, string(row))
+	}
+
+	grid, _ := engine.GridFromRows(rows)
+	return grid
+}
+
+func TestTemplatesFireTheirChains(t *testing.T) {
+	for i := range Library {
+		template := &Library[i]
+		grid := build(template, 0)
+
+		var longest int = 0
+		for choice := range engine.Choices {
+			position, rotation := engine.ChoiceToAction(choice)
+			for colour := uint8(1); colour <= 5; colour++ {
+				var placed engine.Grid = grid
+				resolution, err := engine.Place(&placed, position, rotation, [2]uint8{colour, 5})
+				if err == nil && resolution.ChainCount > longest {
+					longest = resolution.ChainCount
+				}
+			}
+		}
+
+		if longest != template.Chain {
+			t.Fatalf("%s fires a chain of %d, expected %d", template.Name, longest, template.Chain)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	stairs := &Library[2]
+
+	complete := build(stairs, 2)
+	fit := Match(&complete, stairs)
+	if fit.Progress() != 100 || fit.Offset != 2 || fit.Conflicts != 0 {
+		t.Fatalf("Complete stairs matched as %+v", fit)
+	}
+
+	// Colours do not matter, only that letters agree, even mirrored
+	mirrored, _ := engine.GridFromRows([]string{
+		"...54.",
+		"...354",
+		"...354",
+		"...354",
+	})
+	fit = Match(&mirrored, stairs)
+	if fit.Progress() != 100 || !fit.Mirrored {
+		t.Fatalf("Mirrored stairs matched as %+v", fit)
+	}
+
+	// Half built with a skull in the way
+	partial, _ := engine.GridFromRows([]string{
+		"32....",
+		"321...",
+		"301...",
+	})
+	fit = Match(&partial, stairs)
+	if fit.Filled != 7 || fit.Conflicts != 1 || fit.Progress() != (7-2)*100/11 {
+		t.Fatalf("Partial stairs matched as %+v", fit)
+	}
+
+	empty, _ := engine.GridFromRows(nil)
+	if best := Best(&empty); best.Progress() != 0 {
+		t.Fatalf("Empty grid should fit nothing, got %+v", best)
+	}
+}
+
+func TestBestSkipsBuriedTemplates(t *testing.T) {
+	// Stairs with a block stacked above them
+	grid, _ := engine.GridFromRows([]string{
+		"4.....",
+		"32....",
+		"321...",
+		"321...",
+		"321...",
+	})
+	stairs := Match(&grid, &Library[2])
+	if stairs.Progress() == 0 || stairs.Offset != 0 {
+		t.Fatalf("Stairs should still match on their own, got %+v", stairs)
+	}
+
+	// Only the columns to the right are low enough to count
+	if best := Best(&grid); best.Offset == 0 || best.Progress() >= stairs.Progress() {
+		t.Fatalf("Buried stairs matched %+v", best)
+	}
+}
+
+func TestBestWeighsChains(t *testing.T) {
+	grid, _ := engine.GridFromRows([]string{
+		"323...",
+		"2231..",
+	})
+
+	// Further into the sandwich, but the stairs fire a longer chain
+	sandwich, stairs := Match(&grid, &Library[0]), Match(&grid, &Library[2])
+	if sandwich.Progress() <= stairs.Progress() {
+		t.Fatalf("Expected more of the sandwich built, %d against %d", sandwich.Progress(), stairs.Progress())
+	}
+
+	if best := Best(&grid); best.Template != &Library[2] || best.Value() != stairs.Value() {
+		t.Fatalf("Expected the stairs, got %+v", best)
+	}
+}
+
+func BenchmarkBest(b *testing.B) {
+	grid, _ := engine.GridFromRows([]string{
+		"32.4..",
+		"321.5.",
+		"301145",
+	})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Best(&grid)
+	}
+}
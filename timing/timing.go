@@ -0,0 +1,119 @@
+// Package timing decides when a chain is worth firing: keep building towards
+// a bigger one, or fire what there is now.
+package timing
+
+// Decision is what to do with a placement that sets off a chain.
+type Decision int
+
+const (
+	BUILD Decision = iota
+	FIRE
+)
+
+func (decision Decision) String() string {
+	if decision == FIRE {
+		return "fire"
+	}
+	return "build"
+}
+
+// Situation is what the policy looks at at the start of a turn.
+type Situation struct {
+	// Empty rows left in the spawn column
+	Headroom int
+
+	// Our grid is close enough to topping out that staying alive comes first
+	InDanger bool
+
+	// Skulls that landed on our grid since the last turn
+	IncomingSkulls int
+
+	OpponentHeadroom  int
+	OpponentFreeCells int
+
+	// Longest chain the opponent can set off with the next pair
+	OpponentChain int
+}
+
+// Policy sets the chain length to aim for from the situation.
+type Policy struct {
+	// Chain length aimed for when nothing presses
+	TargetChain int `json:"target_chain"`
+
+	// Aimed for once skulls have landed, to clear them quickly
+	SkullChain int `json:"skull_chain"`
+
+	// With this little headroom the target comes down by one
+	PressureHeadroom int `json:"pressure_headroom"`
+
+	// An opponent able to fire a chain this long is met with the skull chain,
+	// getting ours off before theirs buries it
+	ThreatChain int `json:"threat_chain"`
+
+	// With the opponent this close to topping out any chain may finish it
+	KillHeadroom  int `json:"kill_headroom"`
+	KillFreeCells int `json:"kill_free_cells"`
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		TargetChain:      4,
+		SkullChain:       2,
+		PressureHeadroom: 6,
+		ThreatChain:      3,
+		KillHeadroom:     2,
+		KillFreeCells:    12,
+	}
+}
+
+// Target returns the chain length worth firing in the situation.
+func (policy *Policy) Target(situation Situation) int {
+	if situation.InDanger {
+		return 1
+	}
+
+	if situation.OpponentHeadroom <= policy.KillHeadroom || situation.OpponentFreeCells <= policy.KillFreeCells {
+		return 1
+	}
+
+	var target int = policy.TargetChain
+	var threatened bool = policy.ThreatChain > 0 && situation.OpponentChain >= policy.ThreatChain
+	if (situation.IncomingSkulls > 0 || threatened) && policy.SkullChain < target {
+		target = policy.SkullChain
+	}
+
+	if situation.Headroom <= policy.PressureHeadroom {
+		target--
+	}
+
+	if target < 1 {
+		return 1
+	}
+
+	return target
+}
+
+// Decide says whether a placement setting off chainCount steps should fire
+// now against the target, or whether it is better kept while building.
+func Decide(chainCount int, target int) Decision {
+	if chainCount > 0 && chainCount >= target {
+		return FIRE
+	}
+
+	return BUILD
+}
+
+// Score rates a placement's chain against the target: firing at or above
+// it is rewarded, the longer the better, while setting off a shorter chain
+// throws away what was being built.
+func Score(chainCount int, target int) int {
+	if chainCount == 0 {
+		return 0
+	}
+
+	if Decide(chainCount, target) == FIRE {
+		return 2 + chainCount - target
+	}
+
+	return -2 * (target - chainCount)
+}
@@ -0,0 +1,75 @@
+package timing
+
+import (
+	"testing"
+)
+
+func calm() Situation {
+	return Situation{
+		Headroom:          12,
+		OpponentHeadroom:  12,
+		OpponentFreeCells: 72,
+	}
+}
+
+func TestTarget(t *testing.T) {
+	policy := DefaultPolicy()
+
+	tests := []struct {
+		name      string
+		situation func(situation *Situation)
+		target    int
+	}{
+		{"calm", func(situation *Situation) {}, 4},
+		{"skulls landed", func(situation *Situation) { situation.IncomingSkulls = 6 }, 2},
+		{"getting high", func(situation *Situation) { situation.Headroom = 5 }, 3},
+		{"skulls and high", func(situation *Situation) { situation.IncomingSkulls = 6; situation.Headroom = 5 }, 1},
+		{"in danger", func(situation *Situation) { situation.InDanger = true }, 1},
+		{"opponent nearly out", func(situation *Situation) { situation.OpponentHeadroom = 2 }, 1},
+		{"opponent nearly full", func(situation *Situation) { situation.OpponentFreeCells = 10 }, 1},
+		{"opponent building", func(situation *Situation) { situation.OpponentChain = 2 }, 4},
+		{"opponent can fire", func(situation *Situation) { situation.OpponentChain = 3 }, 2},
+		{"opponent can fire and high", func(situation *Situation) { situation.OpponentChain = 4; situation.Headroom = 5 }, 1},
+	}
+
+	for _, test := range tests {
+		situation := calm()
+		test.situation(&situation)
+		if target := policy.Target(situation); target != test.target {
+			t.Fatalf("%s: expected target %d, got %d", test.name, test.target, target)
+		}
+	}
+}
+
+func TestDecideAndScore(t *testing.T) {
+	tests := []struct {
+		chainCount int
+		target     int
+		decision   Decision
+		score      int
+	}{
+		{0, 4, BUILD, 0},
+		{1, 4, BUILD, -6},
+		{3, 4, BUILD, -2},
+		{4, 4, FIRE, 2},
+		{5, 4, FIRE, 3},
+		{1, 1, FIRE, 2},
+	}
+
+	for _, test := range tests {
+		if decision := Decide(test.chainCount, test.target); decision != test.decision {
+			t.Fatalf("%d against %d: expected %v, got %v", test.chainCount, test.target, test.decision, decision)
+		}
+
+		if score := Score(test.chainCount, test.target); score != test.score {
+			t.Fatalf("%d against %d: expected score %d, got %d", test.chainCount, test.target, test.score, score)
+		}
+	}
+
+	// Closer to the target is always better while building
+	for chainCount := 1; chainCount < 4; chainCount++ {
+		if Score(chainCount, 4) >= Score(chainCount+1, 4) {
+			t.Fatalf("Chain of %d should score below a chain of %d", chainCount, chainCount+1)
+		}
+	}
+}